@@ -34,7 +34,7 @@ export CGO_ENABLED=0
 echo "编译参数: GOOS=${GOOS}, GOARCH=${GOARCH}, CGO_ENABLED=${CGO_ENABLED}"
 
 cd cmd
-go build -ldflags="-s -w" -tags netgo -o "../${BUILD_DIR}/${BINARY_NAME}" main.go
+go build -ldflags="-s -w" -tags netgo -o "../${BUILD_DIR}/${BINARY_NAME}" .
 cd ..
 
 if [ $? -ne 0 ]; then
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"hardware-test/pkg/cardreader"
+	"hardware-test/pkg/config"
+)
+
+// newCardReader 按配置创建读卡器，连接信息输出到 out
+func newCardReader(out io.Writer, cfg config.CardReaderConfig) *cardreader.Reader {
+	reader := cardreader.NewReader(cfg.VID, cfg.PID)
+	reader.SetOutput(out)
+	if cfg.BaudRate > 0 {
+		reader.SetBaudRate(cfg.BaudRate)
+	}
+	reader.SetDevice(cfg.Device)
+	fmt.Fprintf(out, "连接读卡器: VID=0x%04X, PID=0x%04X\n", cfg.VID, cfg.PID)
+	return reader
+}
+
+// listHIDDevices 列出与配置的 VID/PID 匹配的 HID 设备
+func listHIDDevices(cfg config.CardReaderConfig) int {
+	devices := cardreader.List(cfg.VID, cfg.PID)
+	if len(devices) == 0 {
+		fmt.Printf("未找到 HID 设备 (VID: 0x%04X, PID: 0x%04X)\n", cfg.VID, cfg.PID)
+		return 1
+	}
+
+	fmt.Printf("找到 %d 个 HID 设备:\n", len(devices))
+	for i, d := range devices {
+		fmt.Printf("  [%d] %s\n", i+1, d)
+	}
+	return 0
+}
+
+// watchCardReader 持续等待刷卡并输出卡号，按 Ctrl+C 结束
+//
+// 没有读到任何卡时测试失败，响应为最后一次读到的卡号。
+func watchCardReader(out io.Writer, cfg config.CardReaderConfig) ([]byte, error) {
+	if cfg.VID == 0 || cfg.PID == 0 {
+		return nil, fmt.Errorf("读卡器测试需要 -vid 和 -pid 参数")
+	}
+	dec, err := cardreader.NewDecoder(cfg.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := newCardReader(out, cfg)
+	if err := reader.Connect(); err != nil {
+		return nil, err
+	}
+	defer reader.Disconnect()
+	warnInit(out, reader)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return watchCards(ctx, out, reader, dec)
+}
+
+// warnInit 读卡器初始化未确认时输出警告，刷卡读取照常进行
+func warnInit(out io.Writer, reader *cardreader.Reader) {
+	if err := reader.InitErr(); err != nil {
+		fmt.Fprintf(out, "警告: 读卡器初始化未确认: %v\n", err)
+	}
+}
+
+// watchCards 在已连接的读卡器上等待刷卡并输出卡号，直到 ctx 结束
+//
+// 没有读到任何卡时返回错误，响应为最后一次读到的卡号。
+func watchCards(ctx context.Context, out io.Writer, reader *cardreader.Reader, dec *cardreader.Decoder) ([]byte, error) {
+	fmt.Fprintln(out, "等待刷卡 (按 Ctrl+C 结束) ...")
+	var last []byte
+	count := 0
+	err := reader.Watch(ctx, dec, cardreader.DefaultHoldoff, func(card cardreader.Card) {
+		count++
+		last = card.UID
+		fmt.Fprintf(out, "[%s] 卡号: %s\n", card.Time.Format("15:04:05.000"), card)
+	})
+	if err != nil {
+		return last, err
+	}
+
+	fmt.Fprintf(out, "\n共读到 %d 次刷卡\n", count)
+	if count == 0 {
+		return nil, fmt.Errorf("未读到卡片")
+	}
+	return last, nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"hardware-test/pkg/transport"
+)
+
+// defaultDiscoverPorts 默认探测的端口
+const defaultDiscoverPorts = "8080,8081,8086"
+
+// maxDiscoverHosts 单次发现最多扫描的主机数 (/16)
+const maxDiscoverHosts = 1 << 16
+
+// discoverHit 在某个地址和端口上探测到的设备
+type discoverHit struct {
+	Host string
+	Port int
+	probeHit
+}
+
+// runDiscover 执行 discover 子命令: 扫描网段内各主机的端口，按应答帧识别 RFID 读写器、锁控板和串口屏
+func runDiscover(args []string) int {
+	fs := flag.NewFlagSet("discover", flag.ExitOnError)
+	subnet := fs.String("subnet", "", "要扫描的网段 (CIDR，如 192.168.1.0/24)")
+	portList := fs.String("ports", defaultDiscoverPorts, "要探测的端口 (逗号分隔)")
+	dialTimeout := fs.Duration("dial-timeout", 500*time.Millisecond, "TCP 连接超时")
+	timeout := fs.Duration("timeout", 500*time.Millisecond, "每次探测等待应答的时间")
+	workers := fs.Int("workers", 64, "并发探测数")
+	verbose := fs.Bool("v", false, "输出端口开放但无法识别的地址")
+	fs.Parse(args)
+
+	if *subnet == "" {
+		fmt.Println("✗ 需要指定 -subnet 参数，如 -subnet 192.168.1.0/24")
+		return 1
+	}
+	hosts, err := subnetHosts(*subnet)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	ports, err := parsePortList(*portList)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	if *workers <= 0 {
+		*workers = 1
+	}
+
+	fmt.Printf("扫描 %s (%d 个地址), 端口 %v ...\n", *subnet, len(hosts), ports)
+
+	type target struct {
+		host string
+		port int
+	}
+	targets := make(chan target)
+	var (
+		mu   sync.Mutex
+		hits []discoverHit
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range targets {
+				dial := func() (transport.Transport, error) {
+					return transport.DialTCP(t.host, t.port, *dialTimeout)
+				}
+				hit, open := classifyEndpoint(dial, *timeout)
+				if !open {
+					continue
+				}
+
+				mu.Lock()
+				addr := net.JoinHostPort(t.host, fmt.Sprint(t.port))
+				if hit.Device != "" {
+					hits = append(hits, discoverHit{Host: t.host, Port: t.port, probeHit: hit})
+					fmt.Printf("✓ %s: %s (应答 % X)\n", addr, hit.Device, hit.Response)
+				} else if *verbose {
+					fmt.Printf("  %s: 端口开放，无法识别\n", addr)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, h := range hosts {
+		for _, p := range ports {
+			targets <- target{h.String(), p}
+		}
+	}
+	close(targets)
+	wg.Wait()
+
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := net.ParseIP(hits[i].Host).To16(), net.ParseIP(hits[j].Host).To16()
+		if c := bytes.Compare(a, b); c != 0 {
+			return c < 0
+		}
+		return hits[i].Port < hits[j].Port
+	})
+
+	fmt.Printf("\n========== 发现结果 ==========\n")
+	if len(hits) == 0 {
+		fmt.Println("未发现 RFID 读写器、锁控板或串口屏")
+		return 1
+	}
+	for _, h := range hits {
+		fmt.Printf("  %-8s %s\n", h.Device, net.JoinHostPort(h.Host, fmt.Sprint(h.Port)))
+	}
+	fmt.Println("\n配置文件示例:")
+	for _, h := range hits {
+		if h.Device == deviceRFID {
+			fmt.Printf("[%s]\nhost = %q\nport = %d\n\n", h.Device, h.Host, h.Port)
+		} else {
+			fmt.Printf("[%s]\ntype = \"socket\"\nhost = %q\nport = %d\n\n", h.Device, h.Host, h.Port)
+		}
+	}
+	return 0
+}
+
+// classifyEndpoint 依次用 RFID、锁控板、屏幕的查询帧探测端点
+//
+// 端口无法连接时 open 为 false；能连接但都没有正确应答时返回空的 probeHit。
+func classifyEndpoint(dial transport.Dialer, timeout time.Duration) (hit probeHit, open bool) {
+	for _, device := range []string{deviceRFID, deviceLock, deviceScreen} {
+		hit, ok, err := probeDevice(device, dial, timeout)
+		if err != nil {
+			// 第一次连接就失败说明端口未开放；之后失败可能是设备关闭了连接，继续尝试
+			if device == deviceRFID {
+				return probeHit{}, false
+			}
+			continue
+		}
+		if ok {
+			return hit, true
+		}
+	}
+	return probeHit{}, true
+}
+
+// parsePortList 解析逗号分隔的 TCP 端口列表，端口必须在 1-65535 之间
+func parsePortList(s string) ([]int, error) {
+	ports, err := parseIntList(s)
+	if err != nil {
+		return nil, fmt.Errorf("无效的端口列表: %w", err)
+	}
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("无效的端口列表: %q", s)
+	}
+	for _, p := range ports {
+		if p > 65535 {
+			return nil, fmt.Errorf("端口超出范围 (1-65535): %d", p)
+		}
+	}
+	return ports, nil
+}
+
+// subnetHosts 返回网段内的主机地址 (不含网络地址和广播地址)
+func subnetHosts(cidr string) ([]net.IP, error) {
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("无效的网段: %s", cidr)
+	}
+	ip = ip.To4()
+	if ip == nil {
+		return nil, fmt.Errorf("只支持 IPv4 网段: %s", cidr)
+	}
+
+	ones, bits := ipnet.Mask.Size()
+	size := 1 << (bits - ones)
+	if size > maxDiscoverHosts {
+		return nil, fmt.Errorf("网段过大: %s (最大 /16)", cidr)
+	}
+
+	base := ipnet.IP.To4()
+	start := uint32(base[0])<<24 | uint32(base[1])<<16 | uint32(base[2])<<8 | uint32(base[3])
+	first, last := 0, size-1
+	if size > 2 {
+		// 去掉网络地址和广播地址
+		first, last = 1, size-2
+	}
+
+	hosts := make([]net.IP, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		n := start + uint32(i)
+		hosts = append(hosts, net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n)).To4())
+	}
+	return hosts, nil
+}
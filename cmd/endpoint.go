@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"hardware-test/pkg/config"
+)
+
+// endpointFlags 子命令共用的设备连接参数
+type endpointFlags struct {
+	fs         *flag.FlagSet
+	configPath *string
+	host       *string
+	port       *int
+	serialPort *string
+	baudRate   *int
+}
+
+// addEndpointFlags 在子命令的 FlagSet 上注册连接参数
+func addEndpointFlags(fs *flag.FlagSet) *endpointFlags {
+	return &endpointFlags{
+		fs:         fs,
+		configPath: fs.String("config", "config.toml", "配置文件路径 (命令行参数优先于配置文件)"),
+		host:       fs.String("host", "", "设备地址 (socket 连接)"),
+		port:       fs.Int("port", 0, "端口号 (socket 连接)"),
+		serialPort: fs.String("serial", "", "串口路径 (串口连接，未指定时锁控板默认 /dev/ttyS0，串口屏默认 /dev/ttyS1)"),
+		baudRate:   fs.Int("baud", 115200, "波特率 (串口连接)"),
+	}
+}
+
+// resolve 加载配置文件，取出指定设备的端点并用显式指定的参数覆盖
+//
+// 返回的配置中该端点已被覆盖，可继续读取设备的其他配置项。
+func (f *endpointFlags) resolve(pick func(*config.Config) *config.Endpoint) (*config.Config, config.Endpoint, error) {
+	cfg, err := loadConfig(os.Stdout, *f.configPath, isFlagSet(f.fs, "config"))
+	if err != nil {
+		return nil, config.Endpoint{}, err
+	}
+
+	ep := pick(cfg)
+	f.apply(ep)
+	if err := ep.Validate(); err != nil {
+		return nil, config.Endpoint{}, err
+	}
+	return cfg, *ep, nil
+}
+
+// set 是否显式指定了任一连接参数 (-config 除外)
+func (f *endpointFlags) set() bool {
+	return isFlagSet(f.fs, "host") || isFlagSet(f.fs, "port") ||
+		isFlagSet(f.fs, "serial") || isFlagSet(f.fs, "baud")
+}
+
+// apply 用显式指定的连接参数覆盖端点
+func (f *endpointFlags) apply(ep *config.Endpoint) {
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "host":
+			ep.Host, ep.Type = *f.host, config.TypeSocket
+		case "port":
+			ep.Port = *f.port
+		case "serial":
+			ep.SerialPort = *f.serialPort
+		case "baud":
+			ep.BaudRate = *f.baudRate
+		}
+	})
+	// 只指定串口时切换为串口连接 (同时指定 -host 时 Socket 优先)
+	if isFlagSet(f.fs, "serial") && !isFlagSet(f.fs, "host") {
+		ep.Type = config.TypeSerial
+	}
+}
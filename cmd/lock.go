@@ -0,0 +1,161 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"hardware-test/pkg/config"
+	"hardware-test/pkg/lock"
+)
+
+// runLock 执行 lock 子命令，返回进程退出码
+func runLock(args []string) int {
+	if len(args) == 0 {
+		printLockUsage()
+		return 1
+	}
+
+	switch args[0] {
+	case "open":
+		return runLockOpen(args[1:])
+	case "status":
+		return runLockStatus(args[1:])
+	default:
+		fmt.Printf("未知的 lock 子命令: %s\n", args[0])
+		printLockUsage()
+		return 1
+	}
+}
+
+func printLockUsage() {
+	fmt.Println("用法:")
+	fmt.Println("  hardware-test lock open -board N -lock N [-to N] [-delay 500ms] [-verify=true] [连接参数]")
+	fmt.Println("  hardware-test lock status -board N [连接参数]")
+	fmt.Println("\n连接参数:")
+	fmt.Println("  -config config.toml  -host HOST -port PORT  -serial /dev/ttyS0 -baud 115200")
+	fmt.Println("\n示例:")
+	fmt.Println("  # 打开 1 号板的 3 号锁")
+	fmt.Println("  hardware-test lock open -board 1 -lock 3")
+	fmt.Println("\n  # 依次打开 1 号板的 1-16 号锁，每把间隔 1 秒并检查开锁状态")
+	fmt.Println("  hardware-test lock open -board 1 -lock 1 -to 16 -delay 1s")
+}
+
+// connectLock 按连接参数创建并连接锁控板控制器
+func connectLock(ef *endpointFlags) (*lock.Controller, error) {
+	_, ep, err := ef.resolve(func(cfg *config.Config) *config.Endpoint { return &cfg.Lock.Endpoint })
+	if err != nil {
+		return nil, fmt.Errorf("锁控板连接参数无效: %w", err)
+	}
+
+	controller := newLockController(ep)
+	if err := controller.Connect(); err != nil {
+		return nil, err
+	}
+	return controller, nil
+}
+
+// newLockController 按端点创建锁控板控制器
+func newLockController(ep config.Endpoint) *lock.Controller {
+	fmt.Printf("连接锁控板: %s\n", ep)
+	if ep.IsSocket() {
+		return lock.NewController(lock.TypeSocket, ep.Host, 0, ep.Port)
+	}
+	return lock.NewController(lock.TypeSerial, ep.SerialPort, ep.BaudRate, 0)
+}
+
+// runLockOpen 打开单个锁或依次打开一段锁，并通过状态查询确认
+func runLockOpen(args []string) int {
+	fs := flag.NewFlagSet("lock open", flag.ExitOnError)
+	ef := addEndpointFlags(fs)
+	board := fs.Int("board", 1, "板地址")
+	first := fs.Int("lock", 0, "锁地址 (与 -to 一起使用时为起始锁地址)")
+	last := fs.Int("to", 0, "结束锁地址 (指定后依次打开 -lock 到 -to 的所有锁)")
+	delay := fs.Duration("delay", 500*time.Millisecond, "开锁后等待多久再查询状态")
+	verify := fs.Bool("verify", true, "开锁后查询状态确认锁已打开")
+	fs.Parse(args)
+
+	if *first <= 0 {
+		fmt.Println("✗ 需要指定 -lock 参数")
+		return 1
+	}
+	if *last == 0 {
+		*last = *first
+	}
+	if *last < *first {
+		fmt.Printf("✗ 无效的锁范围: %d-%d\n", *first, *last)
+		return 1
+	}
+
+	controller, err := connectLock(ef)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	defer controller.Disconnect()
+
+	failCount := 0
+	for lockAddr := *first; lockAddr <= *last; lockAddr++ {
+		if err := controller.Open(*board, lockAddr); err != nil {
+			fmt.Printf("✗ 板 %d, 锁 %d: 开锁命令发送失败: %v\n", *board, lockAddr, err)
+			failCount++
+			continue
+		}
+		time.Sleep(*delay)
+
+		if !*verify {
+			fmt.Printf("→ 板 %d, 锁 %d: 已发送开锁命令\n", *board, lockAddr)
+			continue
+		}
+
+		status, err := controller.QueryStatus(*board)
+		if err != nil {
+			fmt.Printf("✗ 板 %d, 锁 %d: 状态查询失败: %v\n", *board, lockAddr, err)
+			failCount++
+			continue
+		}
+		state, ok := status.Lock(lockAddr)
+		switch {
+		case !ok:
+			fmt.Printf("✗ 板 %d, 锁 %d: 状态响应中没有该锁\n", *board, lockAddr)
+			failCount++
+		case !state.Open:
+			fmt.Printf("✗ 板 %d, 锁 %d: %s\n", *board, lockAddr, state)
+			failCount++
+		default:
+			fmt.Printf("✓ 板 %d, 锁 %d: %s\n", *board, lockAddr, state)
+		}
+	}
+
+	total := *last - *first + 1
+	fmt.Printf("\n成功: %d, 失败: %d, 总计: %d\n", total-failCount, failCount, total)
+	if failCount > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runLockStatus 查询并输出指定锁控板上每把锁的状态
+func runLockStatus(args []string) int {
+	fs := flag.NewFlagSet("lock status", flag.ExitOnError)
+	ef := addEndpointFlags(fs)
+	board := fs.Int("board", 1, "板地址")
+	fs.Parse(args)
+
+	controller, err := connectLock(ef)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	defer controller.Disconnect()
+
+	status, err := controller.QueryStatus(*board)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	for _, l := range status.Locks {
+		fmt.Printf("板 %d, 锁 %d: %s\n", status.BoardAddr, l.Lock, l)
+	}
+	return 0
+}
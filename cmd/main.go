@@ -3,74 +3,195 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
-	"hardware-test/pkg/cardreader"
+	"hardware-test/pkg/config"
 	"hardware-test/pkg/lock"
-	"hardware-test/pkg/rfid"
+	"hardware-test/pkg/report"
 	"hardware-test/pkg/screen"
 )
 
 func main() {
+	// 子命令
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lock":
+			os.Exit(runLock(os.Args[2:]))
+		case "screen":
+			os.Exit(runScreen(os.Args[2:]))
+		case "scan":
+			os.Exit(runScan(os.Args[2:]))
+		case "discover":
+			os.Exit(runDiscover(os.Args[2:]))
+		case "simulate":
+			os.Exit(runSimulate(os.Args[2:]))
+		case "raw":
+			os.Exit(runRaw(os.Args[2:]))
+		case "shell":
+			os.Exit(runShell(os.Args[2:]))
+		}
+	}
+
 	// 定义命令行参数
+	configPath := flag.String("config", "config.toml", "配置文件路径 (命令行参数优先于配置文件)")
 	module := flag.String("module", "", "要测试的模块: rfid, lock, screen, cardreader, all")
-	host := flag.String("host", "", "设备地址 (用于 rfid, lock, screen 的 socket 连接)")
-	port := flag.Int("port", 0, "端口号 (用于 rfid, lock, screen 的 socket 连接)")
-	serialPort := flag.String("serial", "/dev/ttyS0", "串口路径 (用于 lock, screen 的串口连接，默认: /dev/ttyS0)")
-	baudRate := flag.Int("baud", 115200, "波特率 (用于 lock, screen 的串口连接)")
+	host := flag.String("host", "", "设备地址 (覆盖所选单个模块的 socket 连接)")
+	port := flag.Int("port", 0, "端口号 (覆盖所选单个模块的 socket 连接)")
+	serialPort := flag.String("serial", "", "串口路径 (覆盖所选单个模块的串口连接，未指定时锁控板默认 /dev/ttyS0，串口屏默认 /dev/ttyS1)")
+	baudRate := flag.Int("baud", 115200, "波特率 (覆盖所选单个模块的串口连接)")
 	vid := flag.Int("vid", 0x1A86, "读卡器 VID (十六进制, 如 0x1234, 默认: 0x1A86)")
 	pid := flag.Int("pid", 0xE000, "读卡器 PID (十六进制, 如 0x5678, 默认: 0xE000)")
 	antennas := flag.String("antennas", "1,2,3,4", "RFID 天线列表 (逗号分隔)")
+	reportFormat := flag.String("report", "", "输出机器可读的测试报告: json, junit")
+	reportFile := flag.String("report-file", "", "报告输出文件 (默认输出到标准输出)")
+	inventory := flag.Duration("inventory", 0, "RFID 连续盘点时长 (如 10s)，输出每个天线读到的标签")
+	hidDevice := flag.String("hid-device", "", "按序列号或 hidraw 节点选择读卡器 (如 /dev/hidraw1)")
+	listHID := flag.Bool("list-hid", false, "列出与 -vid/-pid 匹配的 HID 设备后退出 (VID 和 PID 为 0 时列出所有设备)")
+	watch := flag.Bool("watch", false, "读卡器持续等待刷卡并输出卡号，按 Ctrl+C 结束")
 	flag.Parse()
 
-	if *module == "" {
+	if *module == "" && !*listHID {
 		printUsage()
 		os.Exit(1)
 	}
 
-	// 解析天线列表
-	antennaList := parseAntennas(*antennas)
+	// 报告输出到标准输出时，文本日志改为输出到标准错误，保证标准输出可直接解析
+	var out io.Writer = os.Stdout
+	if *reportFormat != "" {
+		if *reportFormat != report.FormatJSON && *reportFormat != report.FormatJUnit {
+			fmt.Printf("✗ 未知报告格式: %s (应为 json 或 junit)\n", *reportFormat)
+			os.Exit(1)
+		}
+		if *reportFile == "" {
+			out = os.Stderr
+		}
+	}
+
+	// 加载配置文件，显式指定的命令行参数覆盖配置
+	cfg, err := loadConfig(out, *configPath, isFlagSet(flag.CommandLine, "config"))
+	if err != nil {
+		fmt.Fprintf(out, "✗ %v\n", err)
+		os.Exit(1)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "vid":
+			cfg.CardReader.VID = *vid
+		case "pid":
+			cfg.CardReader.PID = *pid
+		case "hid-device":
+			cfg.CardReader.Device = *hidDevice
+		case "antennas":
+			cfg.RFID.Antennas = parseAntennas(*antennas)
+		}
+	})
+	// 连接参数只覆盖所选的一个设备的端点
+	ef := &endpointFlags{fs: flag.CommandLine, host: host, port: port, serialPort: serialPort, baudRate: baudRate}
+	if ef.set() {
+		ep, err := flagEndpoint(*module, cfg)
+		if err != nil {
+			fmt.Fprintf(out, "✗ %v\n", err)
+			os.Exit(1)
+		}
+		ef.apply(ep)
+	}
+
+	if *listHID {
+		os.Exit(listHIDDevices(cfg.CardReader))
+	}
 
-	// 根据模块执行测试
-	modules := strings.Split(*module, ",")
-	successCount := 0
-	failCount := 0
+	// 根据模块执行测试，all 展开为每个已配置的设备
+	targets, err := expandModules(*module, cfg)
+	if err == nil {
+		err = cfg.CheckDistinct(targets)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "✗ %v\n", err)
+		os.Exit(1)
+	}
 
-	for _, m := range modules {
-		m = strings.TrimSpace(m)
-		fmt.Printf("\n========== 测试 %s 模块 ==========\n", strings.ToUpper(m))
-		success, err := testModule(m, *host, *port, *serialPort, *baudRate, *vid, *pid, antennaList)
-		if success {
-			fmt.Printf("✓ %s 模块测试通过\n", strings.ToUpper(m))
-			successCount++
+	rep := report.New()
+	for _, m := range targets {
+		fmt.Fprintf(out, "\n========== 测试 %s 模块 ==========\n", strings.ToUpper(m))
+		result := testModule(out, m, cfg, testOptions{Inventory: *inventory, Watch: *watch})
+		if result.Passed {
+			fmt.Fprintf(out, "✓ %s 模块测试通过\n", strings.ToUpper(m))
 		} else {
-			fmt.Printf("✗ %s 模块测试失败: %v\n", strings.ToUpper(m), err)
-			failCount++
+			fmt.Fprintf(out, "✗ %s 模块测试失败: %s\n", strings.ToUpper(m), result.Error)
 		}
+		rep.Add(result)
 	}
+	rep.Finish()
 
-	fmt.Printf("\n========== 测试结果 ==========\n")
-	fmt.Printf("成功: %d, 失败: %d, 总计: %d\n", successCount, failCount, successCount+failCount)
+	fmt.Fprintf(out, "\n========== 测试结果 ==========\n")
+	for _, r := range rep.Results {
+		if r.Passed {
+			fmt.Fprintf(out, "  ✓ %-10s %s (%v)\n", r.Module, r.Endpoint, r.Duration.Round(time.Millisecond))
+		} else {
+			fmt.Fprintf(out, "  ✗ %-10s %s: %s\n", r.Module, r.Endpoint, r.Error)
+		}
+	}
+	failCount := rep.Failed()
+	successCount := len(rep.Results) - failCount
+	fmt.Fprintf(out, "成功: %d, 失败: %d, 总计: %d\n", successCount, failCount, successCount+failCount)
+
+	if *reportFormat != "" {
+		if err := writeReport(out, rep, *reportFormat, *reportFile); err != nil {
+			fmt.Fprintf(out, "✗ %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	if failCount > 0 {
 		os.Exit(1)
 	}
 }
 
+// writeReport 输出测试报告到文件或标准输出，out 为文本日志的输出
+func writeReport(out io.Writer, rep *report.Report, format, path string) error {
+	if path == "" {
+		return rep.Write(os.Stdout, format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建报告文件失败: %w", err)
+	}
+	if err := rep.Write(f, format); err != nil {
+		f.Close()
+		return fmt.Errorf("写入报告失败: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("写入报告失败: %w", err)
+	}
+	fmt.Fprintf(out, "测试报告已写入: %s\n", path)
+	return nil
+}
+
 func printUsage() {
 	fmt.Println("硬件测试工具")
 	fmt.Println("\n用法:")
 	fmt.Println("  hardware-test [选项]")
+	fmt.Println("  hardware-test lock <open|status> [选项]")
+	fmt.Println("  hardware-test screen events [选项]")
+	fmt.Println("  hardware-test scan [-ports /dev/ttyS0,/dev/ttyUSB0] [-bauds 9600,115200] [-timeout 300ms] [-v]")
+	fmt.Println("  hardware-test discover -subnet 192.168.1.0/24 [-ports 8080,8081,8086] [-v]")
+	fmt.Println("  hardware-test simulate [-lock ADDR] [-rfid ADDR] [-screen ADDR] [-pty] [故障注入选项]")
+	fmt.Println("  hardware-test raw [-device lock|screen|rfid] [-frame none|xor|crc|eefc] [-timeout 2s] HEX...")
+	fmt.Println("  hardware-test shell [-config config.toml] [-history ~/.hardware-test_history]")
 	fmt.Println("\n选项:")
+	fmt.Println("  -config string")
+	fmt.Println("        配置文件路径 (默认: config.toml，命令行参数优先)")
 	fmt.Println("  -module string")
 	fmt.Println("        要测试的模块: rfid, lock, screen, cardreader, all")
 	fmt.Println("  -host string")
-	fmt.Println("        设备地址 (用于 socket 连接)")
+	fmt.Println("        设备地址 (socket 连接，只能用于单个模块)")
 	fmt.Println("  -port int")
-	fmt.Println("        端口号 (用于 socket 连接)")
+	fmt.Println("        端口号 (socket 连接，只能用于单个模块)")
 	fmt.Println("  -serial string")
-	fmt.Println("        串口路径 (用于串口连接，默认: /dev/ttyS0)")
+	fmt.Println("        串口路径 (串口连接，只能用于单个模块)")
 	fmt.Println("  -baud int")
 	fmt.Println("        波特率 (默认: 115200)")
 	fmt.Println("  -vid int")
@@ -79,9 +200,23 @@ func printUsage() {
 	fmt.Println("        读卡器 PID (十六进制)")
 	fmt.Println("  -antennas string")
 	fmt.Println("        RFID 天线列表 (默认: 1,2,3,4)")
+	fmt.Println("  -inventory duration")
+	fmt.Println("        RFID 连续盘点时长 (如 10s)，输出每个天线读到的不重复标签")
+	fmt.Println("  -hid-device string")
+	fmt.Println("        按序列号或 hidraw 节点选择读卡器 (同一 VID/PID 有多个读卡器时)")
+	fmt.Println("  -list-hid")
+	fmt.Println("        列出与 -vid/-pid 匹配的 HID 设备 (路径、序列号、接口、产品名) 后退出")
+	fmt.Println("  -watch")
+	fmt.Println("        读卡器持续等待刷卡，输出十进制、十六进制和韦根 26 卡号，按 Ctrl+C 结束")
+	fmt.Println("  -report string")
+	fmt.Println("        输出机器可读的测试报告: json, junit")
+	fmt.Println("  -report-file string")
+	fmt.Println("        报告输出文件 (默认输出到标准输出，文本日志改为输出到标准错误)")
 	fmt.Println("\n示例:")
 	fmt.Println("  # 测试 RFID (socket)")
 	fmt.Println("  hardware-test -module rfid -host 192.168.1.100 -port 8086")
+	fmt.Println("\n  # RFID 盘点 10 秒，检查每个天线都能读到标签")
+	fmt.Println("  hardware-test -module rfid -inventory 10s")
 	fmt.Println("\n  # 测试锁控板 (串口，默认 /dev/ttyS0)")
 	fmt.Println("  hardware-test -module lock")
 	fmt.Println("  # 或指定串口")
@@ -92,8 +227,53 @@ func printUsage() {
 	fmt.Println("  hardware-test -module cardreader")
 	fmt.Println("  # 或指定 VID/PID")
 	fmt.Println("  hardware-test -module cardreader -vid 0x1234 -pid 0x5678")
-	fmt.Println("\n  # 测试所有模块")
-	fmt.Println("  hardware-test -module all")
+	fmt.Println("  # 列出读卡器，按 hidraw 节点分别测试")
+	fmt.Println("  hardware-test -list-hid")
+	fmt.Println("  hardware-test -module cardreader -hid-device /dev/hidraw1")
+	fmt.Println("  # 持续等待刷卡并输出卡号")
+	fmt.Println("  hardware-test -module cardreader -watch")
+	fmt.Println("\n  # 测试所有模块 (各设备使用配置文件中的连接参数)")
+	fmt.Println("  hardware-test -module all -config config.toml")
+	fmt.Println("\n  # 打开 1 号板的 3 号锁 / 依次打开 1-16 号锁并检查状态")
+	fmt.Println("  hardware-test lock open -board 1 -lock 3")
+	fmt.Println("  hardware-test lock open -board 1 -lock 1 -to 16 -delay 1s")
+	fmt.Println("\n  # 扫描所有串口和常用波特率，查找锁控板和串口屏")
+	fmt.Println("  hardware-test scan")
+	fmt.Println("\n  # 扫描网段，查找 Socket 连接的 RFID 读写器、锁控板和串口屏")
+	fmt.Println("  hardware-test discover -subnet 192.168.1.0/24 -ports 8080,8081,8086")
+	fmt.Println("\n  # 启动模拟设备，在另一个终端中测试")
+	fmt.Println("  hardware-test simulate")
+	fmt.Println("  hardware-test -module lock -host 127.0.0.1 -port 9101")
+	fmt.Println("\n  # 发送原始帧 (自动追加异或校验)，输出 2 秒内收到的字节")
+	fmt.Println("  hardware-test raw -frame xor -serial /dev/ttyUSB0 -baud 9600 80 01 00 33")
+	fmt.Println("\n  # 交互模式: 保持设备连接，逐条输入命令 (支持历史和 Tab 补全)")
+	fmt.Println("  hardware-test shell")
+	fmt.Println("\n  # 输出 JUnit XML 报告")
+	fmt.Println("  hardware-test -module all -report junit -report-file result.xml")
+}
+
+// loadConfig 加载配置文件，未显式指定且文件不存在时使用默认配置，加载的文件输出到 out
+func loadConfig(out io.Writer, path string, explicit bool) (*config.Config, error) {
+	if _, err := os.Stat(path); err != nil && os.IsNotExist(err) && !explicit {
+		return config.Default(), nil
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(out, "已加载配置文件: %s\n", path)
+	return cfg, nil
+}
+
+// isFlagSet 判断命令行参数是否被显式指定
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
 
 func parseAntennas(s string) []int {
@@ -112,121 +292,212 @@ func parseAntennas(s string) []int {
 	return antennas
 }
 
-func testModule(module, host string, port int, serialPort string, baudRate int, vid, pid int, antennas []int) (bool, error) {
+// flagEndpoint 返回命令行连接参数要覆盖的端点
+//
+// -host、-port、-serial、-baud 只能描述一个设备，-module 为 all 或包含多个设备时返回错误，
+// 避免所有设备被指向同一个地址。多个设备请在配置文件中分别设置。
+func flagEndpoint(spec string, cfg *config.Config) (*config.Endpoint, error) {
+	var eps []*config.Endpoint
+	for _, m := range strings.Split(spec, ",") {
+		switch strings.TrimSpace(m) {
+		case "rfid":
+			eps = append(eps, &cfg.RFID.Endpoint)
+		case "lock":
+			eps = append(eps, &cfg.Lock.Endpoint)
+		case "screen":
+			eps = append(eps, &cfg.Screen.Endpoint)
+		case "all":
+			return nil, fmt.Errorf("-module all 时不能使用 -host/-port/-serial/-baud，请在配置文件中为每个设备分别设置")
+		}
+	}
+	if len(eps) != 1 {
+		return nil, fmt.Errorf("-host/-port/-serial/-baud 只能用于单个模块 (rfid, lock 或 screen)，多个设备请在配置文件中分别设置")
+	}
+	return eps[0], nil
+}
+
+// expandModules 解析模块列表，all 展开为所有已配置连接参数的设备
+func expandModules(spec string, cfg *config.Config) ([]string, error) {
+	var modules []string
+	for _, m := range strings.Split(spec, ",") {
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
+		}
+		if m != "all" {
+			modules = append(modules, m)
+			continue
+		}
+		if cfg.RFID.Configured() {
+			modules = append(modules, "rfid")
+		}
+		if cfg.Lock.Configured() {
+			modules = append(modules, "lock")
+		}
+		if cfg.Screen.Configured() {
+			modules = append(modules, "screen")
+		}
+		if cfg.CardReader.VID != 0 && cfg.CardReader.PID != 0 {
+			modules = append(modules, "cardreader")
+		}
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("没有可测试的模块，请检查 -module 参数或配置文件")
+	}
+	return modules, nil
+}
+
+// testOptions 模块测试的附加模式
+type testOptions struct {
+	Inventory time.Duration // RFID 连续盘点时长，0 表示只做连接测试
+	Watch     bool          // 读卡器持续等待刷卡
+}
+
+// testModule 使用设备自己的端点测试单个模块，并记录耗时和原始响应，测试过程输出到 out
+func testModule(out io.Writer, module string, cfg *config.Config, opts testOptions) report.Result {
+	result := report.Result{Module: module, StartedAt: time.Now()}
+
+	var err error
 	switch module {
 	case "rfid":
-		return testRFID(host, port, antennas)
+		result.Endpoint = cfg.RFID.String()
+		if opts.Inventory > 0 {
+			result.Response, err = inventoryRFID(out, cfg.RFID, opts.Inventory)
+		} else {
+			result.Response, err = testRFID(out, cfg.RFID)
+		}
 	case "lock":
-		return testLock(host, port, serialPort, baudRate)
+		result.Endpoint = cfg.Lock.String()
+		result.Response, err = testLock(out, cfg.Lock.Endpoint)
 	case "screen":
-		return testScreen(host, port, serialPort, baudRate)
+		result.Endpoint = cfg.Screen.String()
+		result.Response, err = testScreen(out, cfg.Screen)
 	case "cardreader":
-		return testCardReader(vid, pid)
-	case "all":
-		// 测试所有模块
-		allSuccess := true
-		var lastErr error
-
-		if host != "" && port > 0 {
-			// 测试基于 socket 的模块
-			if success, err := testRFID(host, port, antennas); !success {
-				allSuccess = false
-				lastErr = err
-			}
-			if success, err := testLock(host, port, "", 0); !success {
-				allSuccess = false
-				lastErr = err
-			}
-			if success, err := testScreen(host, port, "", 0); !success {
-				allSuccess = false
-				lastErr = err
-			}
-		}
-
-		if vid != 0 && pid != 0 {
-			if success, err := testCardReader(vid, pid); !success {
-				allSuccess = false
-				lastErr = err
-			}
-		}
-
-		return allSuccess, lastErr
+		result.Endpoint = cfg.CardReader.String()
+		if opts.Watch {
+			result.Response, err = watchCardReader(out, cfg.CardReader)
+		} else {
+			result.Response, err = testCardReader(out, cfg.CardReader)
+		}
 	default:
-		return false, fmt.Errorf("未知模块: %s", module)
+		err = fmt.Errorf("未知模块: %s", module)
 	}
+
+	result.Duration = time.Since(result.StartedAt)
+	result.Passed = err == nil
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
 }
 
-func testRFID(host string, port int, antennas []int) (bool, error) {
-	if host == "" || port == 0 {
-		return false, fmt.Errorf("RFID 测试需要 -host 和 -port 参数")
+// testResult 将 TestConnection 的结果转换为错误
+func testResult(success bool, err error) error {
+	if err != nil {
+		return err
 	}
+	if !success {
+		return fmt.Errorf("测试未通过")
+	}
+	return nil
+}
 
-	reader := rfid.NewReader(host, port, antennas)
-	fmt.Printf("连接 RFID 读写器: %s:%d (天线: %v)\n", host, port, antennas)
-	return reader.TestConnection()
+func testRFID(out io.Writer, cfg config.RFIDConfig) ([]byte, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("RFID 连接参数无效: %w (请指定 -host 和 -port 或配置文件 [rfid])", err)
+	}
+
+	reader := newRFIDReader(out, cfg)
+	err := testResult(reader.TestConnection())
+	return reader.LastResponse(), err
 }
 
-func testLock(host string, port int, serialPort string, baudRate int) (bool, error) {
-	var controller *lock.Controller
+func testLock(out io.Writer, cfg config.Endpoint) ([]byte, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("锁控板连接参数无效: %w", err)
+	}
 
-	if host != "" && port > 0 {
-		controller = lock.NewController(lock.TypeSocket, host, 0, port)
-		fmt.Printf("连接锁控板 (Socket): %s:%d\n", host, port)
+	var controller *lock.Controller
+	if cfg.IsSocket() {
+		controller = lock.NewController(lock.TypeSocket, cfg.Host, 0, cfg.Port)
+		fmt.Fprintf(out, "连接锁控板 (Socket): %s:%d\n", cfg.Host, cfg.Port)
 	} else {
-		controller = lock.NewController(lock.TypeSerial, serialPort, baudRate, 0)
-		fmt.Printf("连接锁控板 (串口): %s (波特率: %d)\n", serialPort, baudRate)
+		controller = lock.NewController(lock.TypeSerial, cfg.SerialPort, cfg.BaudRate, 0)
+		fmt.Fprintf(out, "连接锁控板 (串口): %s (波特率: %d)\n", cfg.SerialPort, cfg.BaudRate)
 	}
 
-	success, err := controller.TestConnection()
-	if !success {
-		return false, err
+	controller.SetOutput(out)
+	if err := testResult(controller.TestConnection()); err != nil {
+		return controller.LastResponse(), err
 	}
+	raw := controller.LastResponse()
 
 	if err := controller.Connect(); err != nil {
-		return false, err
+		return raw, err
 	}
 	defer controller.Disconnect()
 
 	allStatus, err := controller.QueryAll()
 	if err != nil {
-		return false, err
+		return raw, err
 	}
 
-	fmt.Printf("\n========== 锁状态报告 ==========\n")
+	fmt.Fprintf(out, "\n========== 锁状态报告 ==========\n")
+	var parseErr error
 	for _, status := range allStatus {
-		fmt.Printf("板地址: 0x%02X\n", status.BoardAddr)
-		fmt.Printf("  命令长度: %d 字节\n", status.Length)
-		fmt.Printf("  原始数据: %X\n", status.Data)
-		fmt.Printf("  十六进制: ")
-		for _, b := range status.Data {
-			fmt.Printf("%02X ", b)
+		raw = append(raw, status.Data...)
+		if status.ParseErr != nil {
+			fmt.Fprintf(out, "板 %d: 响应无效 (%v), 原始数据: % X\n", status.BoardAddr, status.ParseErr, status.Data)
+			parseErr = fmt.Errorf("板地址 %d 响应无效: %w", status.BoardAddr, status.ParseErr)
+			continue
+		}
+		for _, l := range status.Board.Locks {
+			fmt.Fprintf(out, "板 %d, 锁 %d: %s\n", status.BoardAddr, l.Lock, l)
 		}
-		fmt.Println()
+	}
+	if len(allStatus) == 0 {
+		fmt.Fprintln(out, "未收到任何锁控板的状态响应")
 	}
 
-	return true, nil
+	if parseErr != nil {
+		return raw, parseErr
+	}
+	return raw, nil
 }
 
-func testScreen(host string, port int, serialPort string, baudRate int) (bool, error) {
-	var controller *screen.Controller
+func testScreen(out io.Writer, cfg config.ScreenConfig) ([]byte, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("屏幕连接参数无效: %w", err)
+	}
+	encoder, err := screen.NewTextEncoder(cfg.Encoding, cfg.Substitute)
+	if err != nil {
+		return nil, fmt.Errorf("屏幕文本编码配置无效: %w", err)
+	}
 
-	if host != "" && port > 0 {
-		controller = screen.NewController(screen.TypeSocket, host, 0, port)
-		fmt.Printf("连接屏幕 (Socket): %s:%d\n", host, port)
+	var controller *screen.Controller
+	if cfg.IsSocket() {
+		controller = screen.NewController(screen.TypeSocket, cfg.Host, 0, cfg.Port)
+		fmt.Fprintf(out, "连接屏幕 (Socket): %s:%d\n", cfg.Host, cfg.Port)
 	} else {
-		controller = screen.NewController(screen.TypeSerial, serialPort, baudRate, 0)
-		fmt.Printf("连接屏幕 (串口): %s (波特率: %d)\n", serialPort, baudRate)
+		controller = screen.NewController(screen.TypeSerial, cfg.SerialPort, cfg.BaudRate, 0)
+		fmt.Fprintf(out, "连接屏幕 (串口): %s (波特率: %d)\n", cfg.SerialPort, cfg.BaudRate)
+	}
+	controller.SetTextEncoder(encoder)
+	controller.SetOutput(out)
+	if cfg.Verify != "" {
+		controller.SetVerifyVariable(cfg.Verify)
 	}
 
-	return controller.TestConnection()
+	err = testResult(controller.TestConnection())
+	return controller.LastResponse(), err
 }
 
-func testCardReader(vid, pid int) (bool, error) {
-	if vid == 0 || pid == 0 {
-		return false, fmt.Errorf("读卡器测试需要 -vid 和 -pid 参数")
+func testCardReader(out io.Writer, cfg config.CardReaderConfig) ([]byte, error) {
+	if cfg.VID == 0 || cfg.PID == 0 {
+		return nil, fmt.Errorf("读卡器测试需要 -vid 和 -pid 参数")
 	}
 
-	reader := cardreader.NewReader(vid, pid)
-	fmt.Printf("连接读卡器: VID=0x%04X, PID=0x%04X\n", vid, pid)
-	return reader.TestConnection()
+	reader := newCardReader(out, cfg)
+	err := testResult(reader.TestConnection())
+	return reader.LastResponse(), err
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"time"
+
+	"hardware-test/pkg/lock"
+	"hardware-test/pkg/rfid"
+	"hardware-test/pkg/screen"
+	"hardware-test/pkg/transport"
+)
+
+// 探测的设备类型
+const (
+	deviceRFID   = "rfid"
+	deviceLock   = "lock"
+	deviceScreen = "screen"
+)
+
+// probeHit 探测到的设备
+type probeHit struct {
+	Device   string // 设备类型
+	Response []byte // 应答帧
+}
+
+// probeDevice 用一种设备的查询帧探测端点，对端按该设备的协议正确应答时返回 true
+//
+// 每次探测单独建立连接，连接失败时返回错误，调用方可据此跳过该端点。
+func probeDevice(device string, dial transport.Dialer, timeout time.Duration) (probeHit, bool, error) {
+	var resp []byte
+	var err error
+
+	switch device {
+	case deviceLock:
+		c := lock.NewControllerWithDialer(dial)
+		c.SetResponseTimeout(timeout)
+		if err := c.Connect(); err != nil {
+			return probeHit{}, false, err
+		}
+		resp, err = c.Probe()
+		c.Disconnect()
+	case deviceScreen:
+		c := screen.NewControllerWithDialer(dial)
+		if err := c.Connect(); err != nil {
+			return probeHit{}, false, err
+		}
+		resp, err = c.Probe(timeout)
+		c.Disconnect()
+	case deviceRFID:
+		r := rfid.NewReaderWithDialer(dial, nil)
+		if err := r.Connect(); err != nil {
+			return probeHit{}, false, err
+		}
+		// 查询功率是只读命令；确认是 RFID 读写器后才在断开时发送停止命令
+		resp, err = r.Probe(timeout)
+		if err != nil {
+			r.Close()
+		} else {
+			r.Disconnect()
+		}
+	}
+
+	if err != nil {
+		return probeHit{}, false, nil
+	}
+	return probeHit{Device: device, Response: resp}, true, nil
+}
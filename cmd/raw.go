@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"hardware-test/pkg/codec"
+	"hardware-test/pkg/config"
+	"hardware-test/pkg/screen"
+	"hardware-test/pkg/transport"
+)
+
+// 原始帧的封装方式
+const (
+	frameNone = "none" // 原样发送
+	frameXOR  = "xor"  // 末尾追加异或校验 (锁控板)
+	frameCRC  = "crc"  // 前加 5A、末尾追加 CRC-16 (RFID 读写器)
+	frameEEFC = "eefc" // EE + 长度 + EE + 类型 + 数据 + FF + FC (串口屏)
+)
+
+// rawPollInterval 接收时检查 Ctrl+C 的间隔
+const rawPollInterval = 200 * time.Millisecond
+
+// runRaw 执行 raw 子命令: 发送十六进制帧并输出收到的原始字节，返回进程退出码
+func runRaw(args []string) int {
+	fs := flag.NewFlagSet("raw", flag.ExitOnError)
+	ef := addEndpointFlags(fs)
+	device := fs.String("device", "lock", "使用配置文件中哪个设备的连接参数: lock, screen, rfid")
+	frame := fs.String("frame", frameNone, "帧封装: none, xor (锁控板), crc (RFID), eefc (串口屏)")
+	timeout := fs.Duration("timeout", 2*time.Second, "发送后接收应答的时长")
+	fs.Usage = printRawUsage
+	fs.Parse(args)
+
+	data, err := codec.ParseHex(strings.Join(fs.Args(), " "))
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	if len(data) > 0 {
+		if data, err = encodeRawFrame(*frame, data); err != nil {
+			fmt.Printf("✗ %v\n", err)
+			return 1
+		}
+	}
+
+	pick, err := rawEndpoint(*device)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	_, ep, err := ef.resolve(pick)
+	if err != nil {
+		fmt.Printf("✗ 连接参数无效: %v\n", err)
+		return 1
+	}
+
+	cfg := transport.Config{Type: transport.TypeSerial, Address: ep.SerialPort, BaudRate: ep.BaudRate}
+	if ep.IsSocket() {
+		cfg = transport.Config{Type: transport.TypeSocket, Address: ep.Host, Port: ep.Port}
+	}
+	fmt.Printf("连接: %s\n", cfg)
+	conn, err := transport.Open(cfg)
+	if err != nil {
+		fmt.Printf("✗ 连接失败: %v\n", err)
+		return 1
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	start := time.Now()
+	if len(data) > 0 {
+		conn.Flush()
+		if _, err := conn.Write(data); err != nil {
+			fmt.Printf("✗ 发送失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("[%s] → % X (%d 字节)\n", start.Format("15:04:05.000"), data, len(data))
+	}
+
+	total, err := receiveRaw(ctx, conn, start)
+	if err != nil {
+		fmt.Printf("✗ 接收失败: %v\n", err)
+		return 1
+	}
+	fmt.Printf("共收到 %d 字节\n", total)
+	return 0
+}
+
+func printRawUsage() {
+	fmt.Println("用法:")
+	fmt.Println("  hardware-test raw [-device lock] [-frame none|xor|crc|eefc] [-timeout 2s] [连接参数] HEX...")
+	fmt.Println("\n十六进制可用空格、逗号或冒号分隔，可带 0x 前缀；不指定 HEX 时只接收。")
+	fmt.Println("\n帧封装:")
+	fmt.Println("  none  原样发送")
+	fmt.Println("  xor   末尾追加所有字节的异或校验 (锁控板)")
+	fmt.Println("  crc   HEX 为协议控制字 + 长度 + 数据，前加 5A、末尾追加 CRC-16 (RFID 读写器)")
+	fmt.Println("  eefc  HEX 为类型 + 数据，封装为 EE 长度 EE 类型 数据 FF FC (串口屏)")
+	fmt.Println("\n连接参数:")
+	fmt.Println("  -config config.toml  -host HOST -port PORT  -serial /dev/ttyS0 -baud 115200")
+	fmt.Println("\n示例:")
+	fmt.Println("  # 发送锁控板查询命令 80 01 00 33 B2")
+	fmt.Println("  hardware-test raw -frame xor -serial /dev/ttyUSB0 -baud 9600 80 01 00 33")
+	fmt.Println("\n  # 发送 RFID 停止命令 5A 000102FF 0000 604D")
+	fmt.Println("  hardware-test raw -device rfid -frame crc 000102FF 0000")
+	fmt.Println("\n  # 监听屏幕上报 30 秒")
+	fmt.Println("  hardware-test raw -device screen -timeout 30s")
+}
+
+// rawEndpoint 返回从配置中取出指定设备端点的函数
+func rawEndpoint(device string) (func(*config.Config) *config.Endpoint, error) {
+	switch device {
+	case deviceLock:
+		return func(cfg *config.Config) *config.Endpoint { return &cfg.Lock.Endpoint }, nil
+	case deviceScreen:
+		return func(cfg *config.Config) *config.Endpoint { return &cfg.Screen.Endpoint }, nil
+	case deviceRFID:
+		return func(cfg *config.Config) *config.Endpoint { return &cfg.RFID.Endpoint }, nil
+	default:
+		return nil, fmt.Errorf("未知设备: %s (应为 lock, screen 或 rfid)", device)
+	}
+}
+
+// encodeRawFrame 按封装方式生成要发送的帧
+func encodeRawFrame(mode string, data []byte) ([]byte, error) {
+	switch mode {
+	case frameNone:
+		return data, nil
+	case frameXOR:
+		return append(data, codec.XOR(data)), nil
+	case frameCRC:
+		frame := append([]byte{0x5A}, data...)
+		return binary.BigEndian.AppendUint16(frame, codec.CRC16(frame)), nil
+	case frameEEFC:
+		if len(data)-1 > 0xFF-2 {
+			return nil, fmt.Errorf("数据过长: %d 字节 (最多 %d 字节)", len(data)-1, 0xFF-2)
+		}
+		return screen.EncodeFrame(data[0], data[1:]), nil
+	default:
+		return nil, fmt.Errorf("未知的帧封装: %s (应为 none, xor, crc 或 eefc)", mode)
+	}
+}
+
+// receiveRaw 输出收到的每段数据及其相对发送时刻的延迟，直到 ctx 结束
+func receiveRaw(ctx context.Context, conn transport.Transport, start time.Time) (int, error) {
+	total := 0
+	buf := make([]byte, 1024)
+	for ctx.Err() == nil {
+		deadline := time.Now().Add(rawPollInterval)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		conn.SetReadDeadline(deadline)
+
+		n, err := conn.Read(buf)
+		if n > 0 {
+			now := time.Now()
+			total += n
+			fmt.Printf("[%s] ← % X (+%s)\n", now.Format("15:04:05.000"), buf[:n], now.Sub(start).Round(time.Millisecond))
+		}
+		if err != nil && !transport.IsTimeout(err) {
+			return total, err
+		}
+	}
+	return total, nil
+}
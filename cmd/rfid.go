@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"hardware-test/pkg/config"
+	"hardware-test/pkg/rfid"
+)
+
+// newRFIDReader 按配置创建 RFID 读写器，连接和应答信息输出到 out
+func newRFIDReader(out io.Writer, cfg config.RFIDConfig) *rfid.Reader {
+	var reader *rfid.Reader
+	if cfg.IsSocket() {
+		fmt.Fprintf(out, "连接 RFID 读写器: %s:%d (天线: %v)\n", cfg.Host, cfg.Port, cfg.Antennas)
+		reader = rfid.NewReader(cfg.Host, cfg.Port, cfg.Antennas)
+	} else {
+		fmt.Fprintf(out, "连接 RFID 读写器 (串口): %s (波特率: %d, 天线: %v)\n", cfg.SerialPort, cfg.BaudRate, cfg.Antennas)
+		reader = rfid.NewSerialReader(cfg.SerialPort, cfg.BaudRate, cfg.Antennas)
+	}
+	reader.SetOutput(out)
+	return reader
+}
+
+// inventoryRFID 连续盘点指定时长，输出每个天线读到的标签
+//
+// 任一配置的天线没有读到标签时测试失败。
+func inventoryRFID(out io.Writer, cfg config.RFIDConfig, duration time.Duration) ([]byte, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("RFID 连接参数无效: %w", err)
+	}
+
+	reader := newRFIDReader(out, cfg)
+	if err := reader.Connect(); err != nil {
+		return nil, err
+	}
+	defer reader.Disconnect()
+
+	fmt.Fprintf(out, "开始盘点 %v ...\n", duration)
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	return nil, inventoryTags(ctx, out, reader, cfg.Antennas)
+}
+
+// inventoryTags 在已连接的读写器上盘点直到 ctx 结束，输出每个天线读到的标签
+//
+// 任一天线没有读到标签时返回错误。
+func inventoryTags(ctx context.Context, out io.Writer, reader *rfid.Reader, antennas []int) error {
+	// 天线号 -> EPC -> 读取次数
+	seen := make(map[int]map[string]int)
+	err := reader.Inventory(ctx, func(tag rfid.TagReport) {
+		if seen[tag.Antenna] == nil {
+			seen[tag.Antenna] = make(map[string]int)
+		}
+		if seen[tag.Antenna][tag.EPC] == 0 {
+			fmt.Fprintf(out, "[%s] 新标签: %s\n", tag.Time.Format("15:04:05.000"), tag)
+		}
+		seen[tag.Antenna][tag.EPC]++
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\n========== 盘点结果 ==========\n")
+	var missing []int
+	for _, ant := range antennas {
+		tags := seen[ant]
+		fmt.Fprintf(out, "天线 %d: %d 个标签\n", ant, len(tags))
+
+		epcs := make([]string, 0, len(tags))
+		for epc := range tags {
+			epcs = append(epcs, epc)
+		}
+		sort.Strings(epcs)
+		for _, epc := range epcs {
+			fmt.Fprintf(out, "  %s (读取 %d 次)\n", epc, tags[epc])
+		}
+		if len(tags) == 0 {
+			missing = append(missing, ant)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("天线 %v 未读到标签", missing)
+	}
+	return nil
+}
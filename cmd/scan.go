@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hardware-test/pkg/transport"
+)
+
+// defaultScanPatterns 扫描的串口设备
+var defaultScanPatterns = []string{"/dev/ttyS*", "/dev/ttyUSB*", "/dev/ttyACM*"}
+
+// defaultScanBauds 依次尝试的波特率
+const defaultScanBauds = "9600,19200,38400,57600,115200"
+
+// scanHit 在某个串口和波特率上探测到的设备
+type scanHit struct {
+	Port string
+	Baud int
+	probeHit
+}
+
+// runScan 执行 scan 子命令: 在候选串口上依次尝试各波特率，探测锁控板和串口屏
+func runScan(args []string) int {
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	portList := fs.String("ports", "", "要扫描的串口 (逗号分隔，默认 "+strings.Join(defaultScanPatterns, ", ")+")")
+	baudList := fs.String("bauds", defaultScanBauds, "依次尝试的波特率 (逗号分隔)")
+	timeout := fs.Duration("timeout", 300*time.Millisecond, "每次探测等待应答的时间")
+	verbose := fs.Bool("v", false, "输出每次探测的结果")
+	fs.Parse(args)
+
+	ports, err := scanPorts(*portList)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	bauds, err := parseIntList(*baudList)
+	if err != nil || len(bauds) == 0 {
+		fmt.Printf("✗ 无效的波特率列表: %s\n", *baudList)
+		return 1
+	}
+	if len(ports) == 0 {
+		fmt.Println("✗ 未找到候选串口")
+		return 1
+	}
+
+	fmt.Printf("扫描 %d 个串口, 波特率 %v ...\n", len(ports), bauds)
+	var hits []scanHit
+	for _, port := range ports {
+		found := make(map[string]bool)
+	bauds:
+		for _, baud := range bauds {
+			dial := transport.Config{Type: transport.TypeSerial, Address: port, BaudRate: baud}.Dialer()
+			for _, device := range []string{deviceLock, deviceScreen} {
+				// 同一串口上已找到的设备不再用其他波特率探测
+				if found[device] {
+					continue
+				}
+				hit, ok, err := probeDevice(device, dial, *timeout)
+				if err != nil {
+					if *verbose {
+						fmt.Printf("  %s: 无法打开 (%v)\n", port, err)
+					}
+					break bauds
+				}
+				if !ok {
+					if *verbose {
+						fmt.Printf("  %s @ %d: %s 无应答\n", port, baud, device)
+					}
+					continue
+				}
+				found[device] = true
+				hits = append(hits, scanHit{Port: port, Baud: baud, probeHit: hit})
+				fmt.Printf("✓ %s @ %d: %s (应答 % X)\n", port, baud, device, hit.Response)
+			}
+		}
+	}
+
+	fmt.Printf("\n========== 扫描结果 ==========\n")
+	if len(hits) == 0 {
+		fmt.Println("未发现锁控板或串口屏")
+		return 1
+	}
+	for _, h := range hits {
+		fmt.Printf("  %-8s %s @ %d\n", h.Device, h.Port, h.Baud)
+	}
+	fmt.Println("\n配置文件示例:")
+	for _, h := range hits {
+		fmt.Printf("[%s]\ntype = \"serial\"\nserial_port = %q\nbaud_rate = %d\n\n", h.Device, h.Port, h.Baud)
+	}
+	return 0
+}
+
+// scanPorts 返回要扫描的串口，未指定时按默认模式查找
+func scanPorts(list string) ([]string, error) {
+	if list != "" {
+		var ports []string
+		for _, p := range strings.Split(list, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				ports = append(ports, p)
+			}
+		}
+		return ports, nil
+	}
+
+	var ports []string
+	for _, pattern := range defaultScanPatterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(matches, func(i, j int) bool { return naturalLess(matches[i], matches[j]) })
+		ports = append(ports, matches...)
+	}
+	return ports, nil
+}
+
+// naturalLess 按末尾数字排序，使 ttyS2 排在 ttyS10 之前
+func naturalLess(a, b string) bool {
+	ta, na := splitNumberSuffix(a)
+	tb, nb := splitNumberSuffix(b)
+	if ta != tb {
+		return ta < tb
+	}
+	return na < nb
+}
+
+func splitNumberSuffix(s string) (string, int) {
+	i := len(s)
+	for i > 0 && s[i-1] >= '0' && s[i-1] <= '9' {
+		i--
+	}
+	n, _ := strconv.Atoi(s[i:])
+	return s[:i], n
+}
+
+// parseIntList 解析逗号分隔的整数列表
+func parseIntList(s string) ([]int, error) {
+	var list []int
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("无效的数值: %q", p)
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"hardware-test/pkg/config"
+	"hardware-test/pkg/screen"
+)
+
+// runScreen 执行 screen 子命令，返回进程退出码
+func runScreen(args []string) int {
+	if len(args) == 0 {
+		printScreenUsage()
+		return 1
+	}
+
+	switch args[0] {
+	case "events":
+		return runScreenEvents(args[1:])
+	default:
+		fmt.Printf("未知的 screen 子命令: %s\n", args[0])
+		printScreenUsage()
+		return 1
+	}
+}
+
+func printScreenUsage() {
+	fmt.Println("用法:")
+	fmt.Println("  hardware-test screen events [-duration 30s] [连接参数]")
+	fmt.Println("\n连接参数:")
+	fmt.Println("  -config config.toml  -host HOST -port PORT  -serial /dev/ttyS1 -baud 115200")
+	fmt.Println("\n示例:")
+	fmt.Println("  # 持续输出屏幕上报的按钮、页面、文本事件，按 Ctrl+C 结束")
+	fmt.Println("  hardware-test screen events -serial /dev/ttyUSB1")
+}
+
+// connectScreen 按连接参数创建并连接屏幕控制器
+func connectScreen(ef *endpointFlags) (*screen.Controller, error) {
+	cfg, ep, err := ef.resolve(func(cfg *config.Config) *config.Endpoint { return &cfg.Screen.Endpoint })
+	if err != nil {
+		return nil, fmt.Errorf("屏幕连接参数无效: %w", err)
+	}
+
+	sc := cfg.Screen
+	sc.Endpoint = ep
+	controller, err := newScreenController(sc)
+	if err != nil {
+		return nil, err
+	}
+	if err := controller.Connect(); err != nil {
+		return nil, err
+	}
+	return controller, nil
+}
+
+// newScreenController 按配置创建屏幕控制器并设置文本编码
+func newScreenController(cfg config.ScreenConfig) (*screen.Controller, error) {
+	encoder, err := screen.NewTextEncoder(cfg.Encoding, cfg.Substitute)
+	if err != nil {
+		return nil, fmt.Errorf("屏幕文本编码配置无效: %w", err)
+	}
+
+	var controller *screen.Controller
+	if cfg.IsSocket() {
+		controller = screen.NewController(screen.TypeSocket, cfg.Host, 0, cfg.Port)
+	} else {
+		controller = screen.NewController(screen.TypeSerial, cfg.SerialPort, cfg.BaudRate, 0)
+	}
+	controller.SetTextEncoder(encoder)
+	fmt.Printf("连接屏幕: %s\n", cfg.Endpoint)
+	return controller, nil
+}
+
+// runScreenEvents 输出屏幕上报的事件，直到超时或 Ctrl+C
+func runScreenEvents(args []string) int {
+	fs := flag.NewFlagSet("screen events", flag.ExitOnError)
+	ef := addEndpointFlags(fs)
+	duration := fs.Duration("duration", 0, "监听时长，0 表示直到 Ctrl+C")
+	fs.Parse(args)
+
+	controller, err := connectScreen(ef)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	defer controller.Disconnect()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	events, err := controller.Listen()
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	fmt.Println("等待屏幕事件 (按 Ctrl+C 结束) ...")
+
+	count := 0
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("\n共收到 %d 个事件\n", count)
+			return 0
+		case ev, ok := <-events:
+			if !ok {
+				fmt.Printf("✗ 屏幕连接已断开: %v\n", controller.ListenErr())
+				return 1
+			}
+			count++
+			fmt.Printf("[%s] %s  (% X)\n", ev.Time.Format("15:04:05.000"), ev, ev.Raw)
+		}
+	}
+}
@@ -0,0 +1,727 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peterh/liner"
+
+	"hardware-test/pkg/cardreader"
+	"hardware-test/pkg/config"
+	"hardware-test/pkg/lock"
+	"hardware-test/pkg/rfid"
+	"hardware-test/pkg/screen"
+)
+
+// 交互模式中的设备名，连接参数取自配置文件的同名段 (读卡器为 [cardreader])
+const deviceCard = "card"
+
+// shellCommand 交互模式的一条命令，用于帮助和 Tab 补全
+type shellCommand struct {
+	name  string
+	subs  []string // 子命令或参数候选
+	usage []string // 帮助中每行的用法说明
+}
+
+var shellCommands = []shellCommand{
+	{deviceLock, []string{"open", "status"}, []string{
+		"lock open <板> <锁>             打开一把锁并查询状态确认",
+		"lock status [板]                查询锁控板上每把锁的状态 (默认 1 号板)",
+	}},
+	{deviceRFID, []string{"power", "inventory"}, []string{
+		"rfid power                      查询各天线的功率",
+		"rfid inventory [5s]             盘点指定时长，输出每个天线读到的标签",
+	}},
+	{deviceScreen, []string{"text", "value", "page", "get", "events"}, []string{
+		"screen text <控件> <文本>       设置文本，如 screen text t0 \"你好\"",
+		"screen value <控件> <数值>      设置数值，如 screen value n0 25",
+		"screen page <页面>              切换页面",
+		"screen get <变量>               查询变量，如 screen get t0.txt、screen get n0.val",
+		"screen events [30s]             输出屏幕上报的事件",
+	}},
+	{deviceCard, []string{"watch", "list"}, []string{
+		"card watch [30s]                等待刷卡并输出卡号",
+		"card list                       列出与配置的 VID/PID 匹配的 HID 设备",
+	}},
+	{"connect", []string{deviceLock, deviceRFID, deviceScreen, deviceCard}, []string{
+		"connect <设备> [HOST:PORT]      连接设备，可指定 Socket 地址",
+		"connect <设备> [串口 [波特率]]  或串口 (读卡器为序列号或 hidraw 节点)",
+	}},
+	{"disconnect", []string{deviceLock, deviceRFID, deviceScreen, deviceCard, "all"}, []string{
+		"disconnect [设备|all]           断开设备连接 (默认全部)",
+	}},
+	{"devices", nil, []string{
+		"devices                         列出各设备的连接参数和状态",
+	}},
+	{"help", nil, []string{
+		"help                            显示帮助",
+	}},
+	{"exit", nil, []string{
+		"exit                            断开所有设备并退出 (或 quit、Ctrl+D)",
+	}},
+}
+
+// 交互模式中命令的默认参数
+const (
+	shellOpenDelay       = 500 * time.Millisecond // 开锁后等待多久再查询状态
+	shellInventoryTime   = 5 * time.Second        // rfid inventory 默认盘点时长
+	shellResponseTimeout = 2 * time.Second        // 查询命令的应答超时
+)
+
+// shellSession 交互模式会话，设备在第一次使用时连接并保持到 disconnect 或退出
+type shellSession struct {
+	cfg    *config.Config
+	lock   *lock.Controller
+	rfid   *rfid.Reader
+	screen *screen.Controller
+	card   *cardreader.Reader
+}
+
+// runShell 执行 shell 子命令: 交互式地操作设备，返回进程退出码
+func runShell(args []string) int {
+	fs := flag.NewFlagSet("shell", flag.ExitOnError)
+	configPath := fs.String("config", "config.toml", "配置文件路径")
+	historyPath := fs.String("history", defaultHistoryPath(), "命令历史文件，为空时不保存历史")
+	fs.Parse(args)
+
+	cfg, err := loadConfig(os.Stdout, *configPath, isFlagSet(fs, "config"))
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	s := &shellSession{cfg: cfg}
+	defer s.disconnect("all")
+
+	line := liner.NewLiner()
+	defer line.Close()
+	line.SetCtrlCAborts(true)
+	line.SetTabCompletionStyle(liner.TabPrints)
+	line.SetWordCompleter(completeShell)
+
+	if *historyPath != "" {
+		if f, err := os.Open(*historyPath); err == nil {
+			line.ReadHistory(f)
+			f.Close()
+		}
+		defer func() {
+			if f, err := os.Create(*historyPath); err == nil {
+				line.WriteHistory(f)
+				f.Close()
+			}
+		}()
+	}
+
+	fmt.Println("硬件测试交互模式，输入 help 查看命令，Tab 补全，Ctrl+D 退出")
+	for {
+		input, err := line.Prompt("hardware-test> ")
+		if errors.Is(err, liner.ErrPromptAborted) {
+			continue
+		}
+		if err != nil {
+			if err != io.EOF {
+				fmt.Printf("✗ %v\n", err)
+			}
+			fmt.Println()
+			return 0
+		}
+
+		if strings.TrimSpace(input) == "" {
+			continue
+		}
+		line.AppendHistory(strings.TrimSpace(input))
+		words, err := splitShellArgs(input)
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+		if words[0] == "exit" || words[0] == "quit" {
+			return 0
+		}
+
+		// 命令执行期间 Ctrl+C 只结束当前命令 (如盘点、等待刷卡)，不退出交互模式
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		if err := s.exec(ctx, words); err != nil {
+			fmt.Printf("✗ %v\n", err)
+		}
+		stop()
+	}
+}
+
+// defaultHistoryPath 返回默认的命令历史文件 ~/.hardware-test_history
+func defaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".hardware-test_history")
+}
+
+func printShellHelp() {
+	fmt.Println("命令:")
+	for _, c := range shellCommands {
+		for _, u := range c.usage {
+			fmt.Println("  " + u)
+		}
+	}
+	fmt.Println("\n设备在第一次使用时按配置文件连接，之后保持连接直到 disconnect 或退出。")
+	fmt.Println("盘点、等待刷卡等持续运行的命令按 Ctrl+C 结束。")
+}
+
+// completeShell 补全命令名和子命令
+func completeShell(line string, pos int) (head string, completions []string, tail string) {
+	head, tail = line[:pos], line[pos:]
+	words := strings.Fields(head)
+	partial := ""
+	if len(words) > 0 && !strings.HasSuffix(head, " ") {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	head = head[:len(head)-len(partial)]
+
+	var candidates []string
+	switch len(words) {
+	case 0:
+		for _, c := range shellCommands {
+			candidates = append(candidates, c.name)
+		}
+	case 1:
+		for _, c := range shellCommands {
+			if c.name == words[0] {
+				candidates = c.subs
+			}
+		}
+	}
+	for _, c := range candidates {
+		if strings.HasPrefix(c, partial) {
+			completions = append(completions, c+" ")
+		}
+	}
+	return head, completions, tail
+}
+
+// splitShellArgs 按空白拆分命令行，支持单引号、双引号和双引号中的反斜杠转义
+func splitShellArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("引号未闭合")
+	}
+	if inWord {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// exec 执行一条命令
+func (s *shellSession) exec(ctx context.Context, words []string) error {
+	cmd, args := words[0], words[1:]
+	sub := ""
+	if len(args) > 0 {
+		sub, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "help":
+		printShellHelp()
+		return nil
+	case "devices":
+		s.printDevices()
+		return nil
+	case "connect":
+		return s.connect(sub, args)
+	case "disconnect":
+		if sub == "" {
+			sub = "all"
+		}
+		return s.disconnect(sub)
+	case deviceLock:
+		return s.execLock(sub, args)
+	case deviceRFID:
+		return s.execRFID(ctx, sub, args)
+	case deviceScreen:
+		return s.execScreen(ctx, sub, args)
+	case deviceCard:
+		return s.execCard(ctx, sub, args)
+	default:
+		return fmt.Errorf("未知命令: %s (输入 help 查看命令)", cmd)
+	}
+}
+
+// printDevices 输出各设备的连接参数和是否已连接
+func (s *shellSession) printDevices() {
+	state := func(connected bool) string {
+		if connected {
+			return "已连接"
+		}
+		return "未连接"
+	}
+	fmt.Printf("  %-7s %-36s %s\n", deviceLock, s.cfg.Lock.Endpoint, state(s.lock != nil))
+	fmt.Printf("  %-7s %-36s %s\n", deviceRFID, s.cfg.RFID.Endpoint, state(s.rfid != nil))
+	fmt.Printf("  %-7s %-36s %s\n", deviceScreen, s.cfg.Screen.Endpoint, state(s.screen != nil))
+	fmt.Printf("  %-7s %-36s %s\n", deviceCard, s.cfg.CardReader, state(s.card != nil))
+}
+
+// connect 按参数修改设备的连接参数并立即 (重新) 连接
+func (s *shellSession) connect(device string, args []string) error {
+	var ep *config.Endpoint
+	switch device {
+	case deviceLock:
+		ep = &s.cfg.Lock.Endpoint
+	case deviceRFID:
+		ep = &s.cfg.RFID.Endpoint
+	case deviceScreen:
+		ep = &s.cfg.Screen.Endpoint
+	case deviceCard:
+		if len(args) > 0 {
+			s.cfg.CardReader.Device = args[0]
+		}
+	default:
+		return fmt.Errorf("用法: connect <lock|rfid|screen|card> [HOST:PORT | 串口 [波特率]]")
+	}
+
+	if ep != nil && len(args) > 0 {
+		next, err := parseShellEndpoint(*ep, args)
+		if err != nil {
+			return err
+		}
+		*ep = next
+	}
+	if err := s.disconnect(device); err != nil {
+		return err
+	}
+
+	var err error
+	switch device {
+	case deviceLock:
+		_, err = s.lockController()
+	case deviceRFID:
+		_, err = s.rfidReader()
+	case deviceScreen:
+		_, err = s.screenController()
+	case deviceCard:
+		_, err = s.cardReader()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ %s 已连接\n", device)
+	return nil
+}
+
+// parseShellEndpoint 解析 connect 命令的地址参数: HOST:PORT 为 Socket，否则为串口路径和可选的波特率
+func parseShellEndpoint(ep config.Endpoint, args []string) (config.Endpoint, error) {
+	if host, port, err := net.SplitHostPort(args[0]); err == nil && !strings.HasPrefix(args[0], "/") {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return ep, fmt.Errorf("无效的端口号: %q", port)
+		}
+		ep.Type, ep.Host, ep.Port = config.TypeSocket, host, p
+	} else {
+		ep.Type, ep.SerialPort = config.TypeSerial, args[0]
+		if len(args) > 1 {
+			baud, err := strconv.Atoi(args[1])
+			if err != nil {
+				return ep, fmt.Errorf("无效的波特率: %q", args[1])
+			}
+			ep.BaudRate = baud
+		}
+	}
+	if err := ep.Validate(); err != nil {
+		return ep, err
+	}
+	return ep, nil
+}
+
+// disconnect 断开指定设备，all 断开所有设备
+func (s *shellSession) disconnect(device string) error {
+	all := device == "all"
+	if (all || device == deviceLock) && s.lock != nil {
+		s.lock.Disconnect()
+		s.lock = nil
+	}
+	if (all || device == deviceRFID) && s.rfid != nil {
+		s.rfid.Disconnect()
+		s.rfid = nil
+	}
+	if (all || device == deviceScreen) && s.screen != nil {
+		s.screen.Disconnect()
+		s.screen = nil
+	}
+	if (all || device == deviceCard) && s.card != nil {
+		s.card.Disconnect()
+		s.card = nil
+	}
+	switch device {
+	case "all", deviceLock, deviceRFID, deviceScreen, deviceCard:
+		return nil
+	default:
+		return fmt.Errorf("未知设备: %s (应为 lock, rfid, screen, card 或 all)", device)
+	}
+}
+
+// lockController 返回已连接的锁控板控制器，未连接时按配置连接
+func (s *shellSession) lockController() (*lock.Controller, error) {
+	if s.lock != nil {
+		return s.lock, nil
+	}
+	if err := s.cfg.Lock.Validate(); err != nil {
+		return nil, fmt.Errorf("锁控板连接参数无效: %w", err)
+	}
+	controller := newLockController(s.cfg.Lock.Endpoint)
+	if err := controller.Connect(); err != nil {
+		return nil, err
+	}
+	s.lock = controller
+	return controller, nil
+}
+
+// rfidReader 返回已连接的 RFID 读写器，未连接时按配置连接
+func (s *shellSession) rfidReader() (*rfid.Reader, error) {
+	if s.rfid != nil {
+		return s.rfid, nil
+	}
+	if err := s.cfg.RFID.Validate(); err != nil {
+		return nil, fmt.Errorf("RFID 连接参数无效: %w", err)
+	}
+	reader := newRFIDReader(os.Stdout, s.cfg.RFID)
+	if err := reader.Connect(); err != nil {
+		return nil, err
+	}
+	s.rfid = reader
+	return reader, nil
+}
+
+// screenController 返回已连接的屏幕控制器，未连接时按配置连接
+func (s *shellSession) screenController() (*screen.Controller, error) {
+	if s.screen != nil {
+		return s.screen, nil
+	}
+	if err := s.cfg.Screen.Validate(); err != nil {
+		return nil, fmt.Errorf("屏幕连接参数无效: %w", err)
+	}
+	controller, err := newScreenController(s.cfg.Screen)
+	if err != nil {
+		return nil, err
+	}
+	if err := controller.Connect(); err != nil {
+		return nil, err
+	}
+	s.screen = controller
+	return controller, nil
+}
+
+// cardReader 返回已连接的读卡器，未连接时按配置连接
+func (s *shellSession) cardReader() (*cardreader.Reader, error) {
+	if s.card != nil {
+		return s.card, nil
+	}
+	if s.cfg.CardReader.VID == 0 || s.cfg.CardReader.PID == 0 {
+		return nil, fmt.Errorf("读卡器需要配置 vid 和 pid")
+	}
+	reader := newCardReader(os.Stdout, s.cfg.CardReader)
+	if err := reader.Connect(); err != nil {
+		return nil, err
+	}
+	warnInit(os.Stdout, reader)
+	s.card = reader
+	return reader, nil
+}
+
+// execLock 执行 lock 命令
+func (s *shellSession) execLock(sub string, args []string) error {
+	switch sub {
+	case "open":
+		if len(args) != 2 {
+			return fmt.Errorf("用法: lock open <板> <锁>")
+		}
+		board, err := parseShellInt("板地址", args[0])
+		if err != nil {
+			return err
+		}
+		lockAddr, err := parseShellInt("锁地址", args[1])
+		if err != nil {
+			return err
+		}
+		controller, err := s.lockController()
+		if err != nil {
+			return err
+		}
+
+		if err := controller.Open(board, lockAddr); err != nil {
+			return fmt.Errorf("板 %d, 锁 %d: 开锁命令发送失败: %w", board, lockAddr, err)
+		}
+		time.Sleep(shellOpenDelay)
+		status, err := controller.QueryStatus(board)
+		if err != nil {
+			return fmt.Errorf("板 %d, 锁 %d: 状态查询失败: %w", board, lockAddr, err)
+		}
+		state, ok := status.Lock(lockAddr)
+		switch {
+		case !ok:
+			return fmt.Errorf("板 %d, 锁 %d: 状态响应中没有该锁", board, lockAddr)
+		case !state.Open:
+			return fmt.Errorf("板 %d, 锁 %d: %s", board, lockAddr, state)
+		}
+		fmt.Printf("✓ 板 %d, 锁 %d: %s\n", board, lockAddr, state)
+		return nil
+
+	case "status":
+		board := 1
+		if len(args) > 0 {
+			var err error
+			if board, err = parseShellInt("板地址", args[0]); err != nil {
+				return err
+			}
+		}
+		controller, err := s.lockController()
+		if err != nil {
+			return err
+		}
+		status, err := controller.QueryStatus(board)
+		if err != nil {
+			return err
+		}
+		for _, l := range status.Locks {
+			fmt.Printf("板 %d, 锁 %d: %s\n", status.BoardAddr, l.Lock, l)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("用法: lock <open|status> ...")
+	}
+}
+
+// execRFID 执行 rfid 命令
+func (s *shellSession) execRFID(ctx context.Context, sub string, args []string) error {
+	switch sub {
+	case "power":
+		reader, err := s.rfidReader()
+		if err != nil {
+			return err
+		}
+		powers, err := reader.Power(shellResponseTimeout)
+		// 功率应答的格式尚未用真机核对，输出原始应答便于对照
+		if raw := reader.LastResponse(); raw != nil {
+			fmt.Printf("应答: % X\n", raw)
+		}
+		if err != nil {
+			return fmt.Errorf("查询功率失败: %w", err)
+		}
+		for _, p := range powers {
+			fmt.Printf("天线 %d: %d dBm\n", p.Antenna, p.Power)
+		}
+		return nil
+
+	case "inventory":
+		duration, err := parseShellDuration(args, shellInventoryTime)
+		if err != nil {
+			return err
+		}
+		reader, err := s.rfidReader()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("开始盘点 %v (按 Ctrl+C 提前结束) ...\n", duration)
+		ctx, cancel := context.WithTimeout(ctx, duration)
+		defer cancel()
+		return inventoryTags(ctx, os.Stdout, reader, s.cfg.RFID.Antennas)
+
+	default:
+		return fmt.Errorf("用法: rfid <power|inventory> ...")
+	}
+}
+
+// screenUsage 需要固定个数参数的 screen 子命令
+var screenUsage = map[string]struct {
+	args int
+	text string
+}{
+	"text":  {2, "screen text <控件> <文本>"},
+	"value": {2, "screen value <控件> <数值>"},
+	"page":  {1, "screen page <页面>"},
+	"get":   {1, "screen get <变量>"},
+}
+
+// execScreen 执行 screen 命令
+func (s *shellSession) execScreen(ctx context.Context, sub string, args []string) error {
+	if usage, ok := screenUsage[sub]; ok && len(args) != usage.args {
+		return fmt.Errorf("用法: %s", usage.text)
+	} else if !ok && sub != "events" {
+		return fmt.Errorf("用法: screen <text|value|page|get|events> ...")
+	}
+
+	controller, err := s.screenController()
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "text":
+		err = controller.SetText(args[0], args[1])
+	case "value":
+		var value int
+		if value, err = parseShellInt("数值", args[1]); err != nil {
+			return err
+		}
+		err = controller.SetValue(args[0], value)
+	case "page":
+		err = controller.SetPage(args[0])
+	case "get":
+		if strings.HasSuffix(args[0], ".txt") {
+			text, err := controller.GetText(args[0], shellResponseTimeout)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s = %q\n", args[0], text)
+			return nil
+		}
+		value, err := controller.GetValue(args[0], shellResponseTimeout)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s = %d\n", args[0], value)
+		return nil
+	case "events":
+		return s.screenEvents(ctx, controller, args)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("✓ 已发送")
+	return nil
+}
+
+// screenEvents 输出屏幕上报的事件直到超时或 Ctrl+C
+//
+// 后台读取只能通过断开连接结束，因此结束后断开屏幕，下一条命令重新连接。
+func (s *shellSession) screenEvents(ctx context.Context, controller *screen.Controller, args []string) error {
+	duration, err := parseShellDuration(args, 0)
+	if err != nil {
+		return err
+	}
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	events, err := controller.Listen()
+	if err != nil {
+		return err
+	}
+	defer s.disconnect(deviceScreen)
+	fmt.Println("等待屏幕事件 (按 Ctrl+C 结束) ...")
+
+	count := 0
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("共收到 %d 个事件\n", count)
+			return nil
+		case ev, ok := <-events:
+			if !ok {
+				return fmt.Errorf("屏幕连接已断开: %v", controller.ListenErr())
+			}
+			count++
+			fmt.Printf("[%s] %s  (% X)\n", ev.Time.Format("15:04:05.000"), ev, ev.Raw)
+		}
+	}
+}
+
+// execCard 执行 card 命令
+func (s *shellSession) execCard(ctx context.Context, sub string, args []string) error {
+	switch sub {
+	case "list":
+		listHIDDevices(s.cfg.CardReader)
+		return nil
+
+	case "watch":
+		duration, err := parseShellDuration(args, 0)
+		if err != nil {
+			return err
+		}
+		dec, err := cardreader.NewDecoder(s.cfg.CardReader.Mode)
+		if err != nil {
+			return err
+		}
+		reader, err := s.cardReader()
+		if err != nil {
+			return err
+		}
+		if duration > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, duration)
+			defer cancel()
+		}
+		_, err = watchCards(ctx, os.Stdout, reader, dec)
+		return err
+
+	default:
+		return fmt.Errorf("用法: card <watch|list> ...")
+	}
+}
+
+// parseShellInt 解析整数参数
+func parseShellInt(name, s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("无效的%s: %q", name, s)
+	}
+	return n, nil
+}
+
+// parseShellDuration 解析可选的时长参数，未指定时返回 def
+func parseShellDuration(args []string, def time.Duration) (time.Duration, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	d, err := time.ParseDuration(args[0])
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("无效的时长: %q (如 5s、1m)", args[0])
+	}
+	return d, nil
+}
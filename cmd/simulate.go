@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"hardware-test/pkg/simulator"
+)
+
+// runSimulate 执行 simulate 子命令: 启动模拟设备直到 Ctrl+C
+func runSimulate(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	lockAddr := fs.String("lock", "127.0.0.1:9101", "模拟锁控板的监听地址 (为空时不启动)")
+	rfidAddr := fs.String("rfid", "127.0.0.1:9103", "模拟 RFID 读写器的监听地址 (为空时不启动)")
+	screenAddr := fs.String("screen", "127.0.0.1:9105", "模拟屏幕的监听地址 (为空时不启动)")
+	usePTY := fs.Bool("pty", false, "同时为每个模拟设备创建伪终端，作为串口使用")
+	boards := fs.String("boards", "1", "模拟锁控板的板地址 (逗号分隔)")
+	locks := fs.Int("locks", simulator.DefaultLocksPerBoard, "每块锁控板的锁数量")
+	openLocks := fs.String("open", "", "初始打开的锁 (板:锁，逗号分隔，如 1:3,2:5)")
+	autoClose := fs.Duration("auto-close", 0, "开锁后自动关闭的时间 (0 表示保持打开)")
+	antennas := fs.Int("antennas", 4, "模拟 RFID 读写器的天线数")
+	tags := fs.String("tags", "E20000000000000000000001@1,E20000000000000000000002@2", "模拟标签 (EPC@天线，逗号分隔)")
+	screenAck := fs.Bool("screen-ack", false, "屏幕指令执行成功时也应答 0x01")
+	delay := fs.Duration("delay", 0, "故障注入: 每帧应答前的延迟")
+	drop := fs.Float64("drop", 0, "故障注入: 丢弃应答的概率 (0-1)")
+	corrupt := fs.Float64("corrupt", 0, "故障注入: 破坏应答的概率 (0-1)，锁控板和 RFID 破坏校验，屏幕 (没有校验) 破坏数据")
+	fs.Parse(args)
+
+	faults := simulator.Faults{Delay: *delay, DropRate: *drop, CorruptRate: *corrupt}
+
+	// 每个模拟设备的名称和实例
+	type device struct {
+		name string
+		addr string
+		dev  simulator.Device
+	}
+	var devices []device
+
+	if *lockAddr != "" {
+		addrs, err := parseIntList(*boards)
+		if err != nil || len(addrs) == 0 {
+			fmt.Printf("✗ 无效的板地址列表: %s\n", *boards)
+			return 1
+		}
+		lb := simulator.NewLockBoards(addrs, *locks)
+		lb.SetAutoClose(*autoClose)
+		lb.SetFaults(faults)
+		if err := setOpenLocks(lb, *openLocks); err != nil {
+			fmt.Printf("✗ %v\n", err)
+			return 1
+		}
+		devices = append(devices, device{"锁控板", *lockAddr, lb})
+	}
+	if *rfidAddr != "" {
+		tagList, err := parseTags(*tags)
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			return 1
+		}
+		r := simulator.NewRFIDReader(*antennas)
+		r.SetTags(tagList)
+		r.SetFaults(faults)
+		devices = append(devices, device{"RFID 读写器", *rfidAddr, r})
+	}
+	if *screenAddr != "" {
+		s := simulator.NewScreen()
+		s.SetAckSuccess(*screenAck)
+		s.SetFaults(faults)
+		devices = append(devices, device{"屏幕", *screenAddr, s})
+	}
+	if len(devices) == 0 {
+		fmt.Println("✗ 没有要启动的模拟设备")
+		return 1
+	}
+
+	var closers []io.Closer
+	defer func() {
+		for _, c := range closers {
+			c.Close()
+		}
+	}()
+	for _, d := range devices {
+		srv, err := simulator.ListenTCP(d.addr, d.dev)
+		if err != nil {
+			fmt.Printf("✗ 启动模拟%s失败: %v\n", d.name, err)
+			return 1
+		}
+		closers = append(closers, srv)
+		fmt.Printf("模拟%s: socket://%s\n", d.name, srv.Addr())
+
+		if *usePTY {
+			p, err := simulator.OpenPTY(d.dev)
+			if err != nil {
+				fmt.Printf("✗ 创建模拟%s的伪终端失败: %v\n", d.name, err)
+				return 1
+			}
+			closers = append(closers, p)
+			fmt.Printf("模拟%s: serial://%s\n", d.name, p.Path())
+		}
+	}
+	if *delay > 0 || *drop > 0 || *corrupt > 0 {
+		fmt.Printf("故障注入: 延迟 %v, 丢弃 %.0f%%, 破坏应答 %.0f%%\n", *delay, *drop*100, *corrupt*100)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Println("模拟设备已启动 (按 Ctrl+C 结束)")
+	<-ctx.Done()
+	return 0
+}
+
+// setOpenLocks 按 "板:锁" 列表设置初始打开的锁
+func setOpenLocks(lb *simulator.LockBoards, spec string) error {
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		b, l, ok := strings.Cut(item, ":")
+		board, err1 := strconv.Atoi(b)
+		lockAddr, err2 := strconv.Atoi(l)
+		if !ok || err1 != nil || err2 != nil {
+			return fmt.Errorf("无效的锁: %q (应为 板:锁)", item)
+		}
+		if err := lb.SetOpen(board, lockAddr, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTags 解析 "EPC@天线" 列表
+func parseTags(spec string) ([]simulator.Tag, error) {
+	var tags []simulator.Tag
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		epc, ant, ok := strings.Cut(item, "@")
+		antenna, err := strconv.Atoi(ant)
+		if !ok || err != nil || epc == "" {
+			return nil, fmt.Errorf("无效的标签: %q (应为 EPC@天线)", item)
+		}
+		tags = append(tags, simulator.Tag{EPC: strings.ToUpper(epc), Antenna: antenna, RSSI: 60})
+	}
+	return tags, nil
+}
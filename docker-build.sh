@@ -31,7 +31,7 @@ docker run --rm \
     -v "$(pwd)/build:/build" \
     -w /workspace \
     ${IMAGE_NAME} \
-    sh -c "cd cmd && go build -ldflags='-s -w' -o /build/hardware-test main.go"
+    sh -c "cd cmd && go build -ldflags='-s -w' -o /build/hardware-test ."
 
 echo "[4/4] 设置执行权限..."
 chmod +x build/hardware-test
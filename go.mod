@@ -5,8 +5,14 @@ go 1.23.0
 toolchain go1.23.4
 
 require (
-	github.com/karalabe/hid v1.0.0
+	github.com/BurntSushi/toml v1.4.0
+	github.com/creack/pty v1.1.24
+	github.com/karalabe/hid v1.0.1-0.20240306101548-573246063e52
+	github.com/peterh/liner v1.2.2
 	github.com/tarm/serial v0.0.0-20180830185346-98f6abe2eb07
+	golang.org/x/sys v0.31.0
+	golang.org/x/term v0.27.0
+	golang.org/x/text v0.21.0
 )
 
-require golang.org/x/sys v0.31.0 // indirect
+require github.com/mattn/go-runewidth v0.0.3 // indirect
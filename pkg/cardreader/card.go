@@ -0,0 +1,73 @@
+package cardreader
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Card 读到的卡片
+type Card struct {
+	UID    []byte    // 卡号字节 (大端)
+	Source string    // 读卡器输出的原始卡号文本 (键盘模式) 或十六进制 UID (原始模式)
+	Time   time.Time // 读取时间
+}
+
+// Number 返回卡号的数值 (UID 超过 8 字节时取低 8 字节)
+func (c Card) Number() uint64 {
+	uid := c.UID
+	if len(uid) > 8 {
+		uid = uid[len(uid)-8:]
+	}
+	var n uint64
+	for _, b := range uid {
+		n = n<<8 | uint64(b)
+	}
+	return n
+}
+
+// Decimal 返回十进制卡号
+func (c Card) Decimal() string {
+	return strconv.FormatUint(c.Number(), 10)
+}
+
+// Hex 返回十六进制卡号
+func (c Card) Hex() string {
+	return fmt.Sprintf("%X", c.UID)
+}
+
+// Wiegand26 返回韦根 26 格式 (卡号低 24 位: 设施码 8 位 + 卡号 16 位) 及带校验位的 26 位码
+//
+// 第 1 位为前 12 位数据的偶校验，第 26 位为后 12 位数据的奇校验。
+func (c Card) Wiegand26() (facility, number uint32, code uint32) {
+	data := uint32(c.Number() & 0xFFFFFF)
+	facility = data >> 16
+	number = data & 0xFFFF
+
+	even := parity(data >> 12)    // 前 12 位中 1 的个数为奇数时补 1
+	odd := parity(data&0xFFF) ^ 1 // 后 12 位中 1 的个数为偶数时补 1
+	code = even<<25 | data<<1 | odd
+	return facility, number, code
+}
+
+// parity 返回 1 的个数的奇偶 (奇数为 1)
+func parity(v uint32) uint32 {
+	var p uint32
+	for ; v != 0; v &= v - 1 {
+		p ^= 1
+	}
+	return p
+}
+
+// String 返回卡号的十进制、十六进制和韦根 26 形式
+func (c Card) String() string {
+	facility, number, code := c.Wiegand26()
+	return fmt.Sprintf("十进制 %s, 十六进制 %s, 韦根26 %03d,%05d (0x%07X)",
+		c.Decimal(), c.Hex(), facility, number, code)
+}
+
+// Equal 判断是否为同一张卡
+func (c Card) Equal(other Card) bool {
+	return bytes.Equal(c.UID, other.UID)
+}
@@ -1,7 +1,11 @@
 package cardreader
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/karalabe/hid"
@@ -11,42 +15,108 @@ import (
 type Reader struct {
 	vid         int
 	pid         int
-	device      *hid.Device
+	baudRate    int
+	selector    string     // 序列号或 hidraw 节点，为空时使用第一个设备
+	info        DeviceInfo // 已连接的设备
+	device      hid.Device
 	isConnected bool
+	initAck     []byte // 初始化特性报告的确认
+	initErr     error  // 初始化未确认的原因，已确认时为 nil
+	out         io.Writer
+
+	// 后台读取协程，每个设备只有一个
+	reports    <-chan readResult
+	stop       chan struct{}
+	readerDone chan struct{}
 }
 
 // NewReader 创建读卡器实例
 func NewReader(vid, pid int) *Reader {
 	return &Reader{
-		vid: vid,
-		pid: pid,
+		vid:      vid,
+		pid:      pid,
+		baudRate: DefaultBaudRate,
+		out:      os.Stdout,
 	}
 }
 
+// SetOutput 设置测试过程信息的输出 (默认标准输出)
+func (r *Reader) SetOutput(w io.Writer) {
+	r.out = w
+}
+
+// SetBaudRate 设置初始化时写入的读卡模块串口波特率 (默认 9600)
+func (r *Reader) SetBaudRate(baudRate int) {
+	r.baudRate = baudRate
+}
+
+// SetDevice 按序列号或 hidraw 节点 (如 /dev/hidraw1) 选择读卡器
+//
+// 同一 VID/PID 有多个读卡器时用于区分，为空时使用枚举到的第一个设备。
+func (r *Reader) SetDevice(selector string) {
+	r.selector = selector
+}
+
+// Device 返回已连接的设备信息
+func (r *Reader) Device() DeviceInfo {
+	return r.info
+}
+
+// LastResponse 返回初始化特性报告的确认
+func (r *Reader) LastResponse() []byte {
+	return r.initAck
+}
+
+// InitErr 返回初始化未确认的原因，已确认时为 nil
+//
+// 读回的确认与设置不一致时为 *InitError。
+func (r *Reader) InitErr() error {
+	return r.initErr
+}
+
 // Connect 连接读卡器
+//
+// 初始化无法完成 (不支持特性报告的平台、没有 hidraw 节点的访问权限等) 时仍然打开设备，
+// 原因通过 InitErr 返回，不影响读取刷卡。
 func (r *Reader) Connect() error {
 	if r.vid == 0 || r.pid == 0 {
 		return fmt.Errorf("无效的 VID/PID")
 	}
+	if r.baudRate <= 0 || r.baudRate > 0xFFFF {
+		return fmt.Errorf("无效的读卡器波特率: %d", r.baudRate)
+	}
 
-	devices := hid.Enumerate(uint16(r.vid), uint16(r.pid))
+	devices := List(r.vid, r.pid)
 	if len(devices) == 0 {
 		return fmt.Errorf("未找到 HID 设备 (VID: 0x%04X, PID: 0x%04X)", r.vid, r.pid)
 	}
+	selected, err := selectDevice(devices, r.selector)
+	if err != nil {
+		return err
+	}
+
+	// 先通过 hidraw 发送初始化特性报告，再打开设备
+	r.initAck, r.initErr = initialize(selected.info, r.baudRate)
 
-	device, err := devices[0].Open()
+	device, err := selected.info.Open()
 	if err != nil {
 		return fmt.Errorf("打开 HID 设备失败: %w", err)
 	}
 
 	r.device = device
+	r.info = selected
 	r.isConnected = true
+	r.startReading()
 	return nil
 }
 
 // Disconnect 断开连接
+//
+// 先等待读取协程退出再关闭设备，关闭时不会有读取仍在使用设备句柄。
 func (r *Reader) Disconnect() error {
 	if r.device != nil {
+		close(r.stop)
+		<-r.readerDone
 		err := r.device.Close()
 		r.device = nil
 		r.isConnected = false
@@ -58,66 +128,56 @@ func (r *Reader) Disconnect() error {
 
 // Read 读取卡片数据
 func (r *Reader) Read() (string, error) {
-	if !r.isConnected {
-		return "", fmt.Errorf("设备未连接")
-	}
+	return r.readHex(context.Background())
+}
 
-	data := make([]byte, 64)
-	n, err := r.device.Read(data)
-	if err != nil {
-		return "", fmt.Errorf("读取数据失败: %w", err)
-	}
+// ReadWithTimeout 读取卡片数据（带超时）
+func (r *Reader) ReadWithTimeout(timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	if n > 0 {
-		return fmt.Sprintf("%X", data[:n]), nil
+	data, err := r.readHex(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "", fmt.Errorf("读取超时")
 	}
-
-	return "", fmt.Errorf("无数据")
+	return data, err
 }
 
-// ReadWithTimeout 读取卡片数据（带超时）
-func (r *Reader) ReadWithTimeout(timeout time.Duration) (string, error) {
-	if !r.isConnected {
-		return "", fmt.Errorf("设备未连接")
+// readHex 读取一个报告并转换为十六进制字符串
+func (r *Reader) readHex(ctx context.Context) (string, error) {
+	report, err := r.ReadContext(ctx)
+	if err != nil {
+		return "", err
 	}
 
-	data := make([]byte, 64)
-	done := make(chan struct{})
-	var result string
-	var err error
-
-	go func() {
-		n, readErr := r.device.Read(data)
-		if readErr == nil && n > 0 {
-			result = fmt.Sprintf("%X", data[:n])
-		}
-		err = readErr
-		close(done)
-	}()
-
-	select {
-	case <-done:
-		if err != nil {
-			return "", fmt.Errorf("读取数据失败: %w", err)
-		}
-		if result != "" {
-			return result, nil
-		}
-		return "", fmt.Errorf("无数据")
-	case <-time.After(timeout):
-		return "", fmt.Errorf("读取超时")
+	if len(report) > 0 {
+		return fmt.Sprintf("%X", report), nil
 	}
+
+	return "", fmt.Errorf("无数据")
 }
 
 // TestConnection 测试连接
+//
+// 读回的初始化确认与设置不一致时失败；无法发送或读回特性报告时只输出警告，
+// 以设备能够打开为通过。
 func (r *Reader) TestConnection() (bool, error) {
 	if err := r.Connect(); err != nil {
 		return false, err
 	}
 
-	fmt.Printf("读卡器已连接 (VID: 0x%04X, PID: 0x%04X)\n", r.vid, r.pid)
-	fmt.Printf("设备信息: %s - %s\n", r.device.Product, r.device.Manufacturer)
-
-	r.Disconnect()
+	fmt.Fprintf(r.out, "读卡器已连接 (VID: 0x%04X, PID: 0x%04X)\n", r.vid, r.pid)
+	fmt.Fprintf(r.out, "设备信息: %s\n", r.info)
+	defer r.Disconnect()
+
+	var initErr *InitError
+	switch {
+	case r.initErr == nil:
+		fmt.Fprintf(r.out, "初始化确认: % X (波特率 %d)\n", r.initAck, r.baudRate)
+	case errors.As(r.initErr, &initErr):
+		return false, r.initErr
+	default:
+		fmt.Fprintf(r.out, "警告: 读卡器初始化未确认，已跳过: %v\n", r.initErr)
+	}
 	return true, nil
 }
@@ -0,0 +1,298 @@
+package cardreader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hardware-test/pkg/codec"
+)
+
+// 报告解析模式
+const (
+	ModeAuto     = "auto"     // 根据报告格式自动判断
+	ModeKeyboard = "keyboard" // 键盘模式: 卡号以按键扫描码输出，回车结束
+	ModeRaw      = "raw"      // 原始模式: 报告携带 UID 的原始字节，一次读卡可能分成多个报告
+)
+
+// DefaultReportGap 原始模式下一次读卡的报告之间的最大间隔
+//
+// 串口转 HID 的读卡器会把一个 UID 拆成多个报告连续发出，超过这一时间没有新报告时，
+// 已收到的数据才作为一个完整的 UID 解码 (见 Decoder.Flush)。
+const DefaultReportGap = 50 * time.Millisecond
+
+// 键盘报告 (HID 启动协议): 修饰键(1) + 保留(1) + 按键(6)
+const keyboardReportLen = 8
+
+// 扫描码
+const (
+	keyA        = 0x04
+	keyZ        = 0x1D
+	key1        = 0x1E
+	key0        = 0x27
+	keyEnter    = 0x28
+	keyTab      = 0x2B
+	keypadEnter = 0x58
+	keypad1     = 0x59
+	keypad0     = 0x62
+)
+
+// Decoder 将 HID 报告解码为卡号
+//
+// 一个卡号通常分散在多个报告中。键盘模式下 Decoder 记录按键状态，只统计新按下的键，
+// 直到回车或 Tab 才输出卡号；原始模式和自动判断时先缓存一次读卡的全部报告，
+// 收到结束符 (回车、换行或键盘的回车键) 时立即解码，否则等调用方在报告间隔超过
+// DefaultReportGap 后调用 Flush。
+type Decoder struct {
+	mode    string
+	text    strings.Builder
+	pressed [6]byte  // 上一个报告中按下的键
+	pending [][]byte // 原始模式和自动判断时尚未解码的报告
+}
+
+// NewDecoder 创建解码器，mode 为空时自动判断
+func NewDecoder(mode string) (*Decoder, error) {
+	switch mode {
+	case "":
+		mode = ModeAuto
+	case ModeAuto, ModeKeyboard, ModeRaw:
+	default:
+		return nil, fmt.Errorf("未知的读卡器模式: %s (应为 auto, keyboard 或 raw)", mode)
+	}
+	return &Decoder{mode: mode}, nil
+}
+
+// Reset 清空未完成的输入
+func (d *Decoder) Reset() {
+	d.text.Reset()
+	d.pressed = [6]byte{}
+	d.pending = nil
+}
+
+// Pending 是否有缓存的报告等待 Flush
+func (d *Decoder) Pending() bool {
+	return len(d.pending) > 0
+}
+
+// Feed 解码一个 HID 报告，读到完整卡号时返回 ok = true
+//
+// 自动判断时不按单个报告判断格式: 缓存的报告全部符合键盘报告格式、且最后一个报告按下了
+// 回车或 Tab 时按键盘模式解码，其余情况等到结束符或 Flush 时按原始模式解码。
+func (d *Decoder) Feed(report []byte) (card Card, ok bool, err error) {
+	if d.mode == ModeKeyboard {
+		return d.feedKeyboard(report)
+	}
+
+	d.pending = append(d.pending, append([]byte(nil), report...))
+	if d.mode == ModeAuto && isKeyboardBurst(d.pending) {
+		return d.flushKeyboard()
+	}
+	if text, ok := rawText(rawData(d.pending)); ok {
+		d.pending = nil
+		card, err := ParseCardNumber(text)
+		return card, err == nil, err
+	}
+	return Card{}, false, nil
+}
+
+// Flush 将缓存的报告作为一次完整的读卡解码，调用方在报告间隔超过 DefaultReportGap 时调用
+func (d *Decoder) Flush() (card Card, ok bool, err error) {
+	if len(d.pending) == 0 {
+		return Card{}, false, nil
+	}
+	uid := rawData(d.pending)
+	d.pending = nil
+	if len(uid) == 0 {
+		return Card{}, false, nil
+	}
+	return Card{UID: uid, Source: fmt.Sprintf("%X", uid), Time: time.Now()}, true, nil
+}
+
+// flushKeyboard 按键盘模式解码缓存的报告
+func (d *Decoder) flushKeyboard() (card Card, ok bool, err error) {
+	reports := d.pending
+	d.pending = nil
+	for _, report := range reports {
+		if card, ok, err = d.feedKeyboard(report); ok || err != nil {
+			return card, ok, err
+		}
+	}
+	return Card{}, false, nil
+}
+
+// feedKeyboard 处理键盘报告
+func (d *Decoder) feedKeyboard(report []byte) (Card, bool, error) {
+	if len(report) < keyboardReportLen {
+		return Card{}, false, fmt.Errorf("键盘报告过短: %d 字节", len(report))
+	}
+
+	var keys [6]byte
+	copy(keys[:], report[2:keyboardReportLen])
+	prev := d.pressed
+	d.pressed = keys
+
+	for _, key := range keys {
+		if key == 0 || containsKey(prev, key) {
+			continue
+		}
+		if key == keyEnter || key == keypadEnter || key == keyTab {
+			text := d.text.String()
+			d.text.Reset()
+			if text == "" {
+				continue
+			}
+			card, err := ParseCardNumber(text)
+			if err != nil {
+				return Card{}, false, err
+			}
+			return card, true, nil
+		}
+		if c := scancodeChar(key); c != 0 {
+			d.text.WriteByte(c)
+		}
+	}
+	return Card{}, false, nil
+}
+
+// ParseCardNumber 解析键盘模式输出的卡号文本
+//
+// 全为数字时按十进制解析，含 A-F 时按十六进制解析。
+func ParseCardNumber(text string) (Card, error) {
+	card := Card{Source: text, Time: time.Now()}
+
+	if n, err := strconv.ParseUint(text, 10, 64); err == nil {
+		card.UID = uidBytes(n)
+		return card, nil
+	}
+
+	s := text
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	uid, err := codec.DecodeHex(s)
+	if err != nil {
+		return Card{}, fmt.Errorf("无法解析卡号: %q", text)
+	}
+	card.UID = uid
+	return card, nil
+}
+
+// uidBytes 将卡号数值转换为大端字节，至少 4 字节
+func uidBytes(n uint64) []byte {
+	size := 4
+	for v := n >> 32; v != 0; v >>= 8 {
+		size++
+	}
+	uid := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		uid[i] = byte(n)
+		n >>= 8
+	}
+	return uid
+}
+
+// scancodeChar 将扫描码转换为卡号字符，非卡号字符返回 0
+func scancodeChar(key byte) byte {
+	switch {
+	case key >= keyA && key <= keyZ:
+		return 'A' + key - keyA
+	case key >= key1 && key < key0:
+		return '1' + key - key1
+	case key == key0 || key == keypad0:
+		return '0'
+	case key >= keypad1 && key < keypad0:
+		return '1' + key - keypad1
+	}
+	return 0
+}
+
+// isKeyboardReport 判断报告是否符合键盘报告格式: 8 字节、保留字节为 0、按键均为卡号字符或结束键
+//
+// 只看格式无法区分碰巧符合格式的原始报告，需要结合 isKeyboardBurst 判断。
+func isKeyboardReport(report []byte) bool {
+	if len(report) != keyboardReportLen || report[1] != 0 {
+		return false
+	}
+	for _, key := range report[2:] {
+		if key != 0 && !isEndKey(key) && scancodeChar(key) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isKeyboardBurst 判断缓存的报告是否为一次完整的键盘输入:
+// 全部符合键盘报告格式，最后一个报告按下了结束键，且结束键前输入过卡号字符
+func isKeyboardBurst(reports [][]byte) bool {
+	hasChar := false
+	for _, report := range reports {
+		if !isKeyboardReport(report) {
+			return false
+		}
+		for _, key := range report[2:] {
+			if scancodeChar(key) != 0 {
+				hasChar = true
+			}
+		}
+	}
+	last := reports[len(reports)-1]
+	for _, key := range last[2:] {
+		if isEndKey(key) {
+			return hasChar
+		}
+	}
+	return false
+}
+
+func isEndKey(key byte) bool {
+	return key == keyEnter || key == keypadEnter || key == keyTab
+}
+
+// rawData 拼接原始模式报告中的数据
+//
+// 首字节为 0xF0|长度 时 (CH9325 串口转 HID 格式) 取其后的数据，否则去掉末尾的 0 填充。
+func rawData(reports [][]byte) []byte {
+	var data []byte
+	for _, report := range reports {
+		if len(report) > 0 && report[0]&0xF0 == 0xF0 {
+			n := int(report[0] & 0x0F)
+			if n > 0 && n < len(report) {
+				data = append(data, report[1:1+n]...)
+				continue
+			}
+		}
+		end := len(report)
+		for end > 0 && report[end-1] == 0 {
+			end--
+		}
+		data = append(data, report[:end]...)
+	}
+	return data
+}
+
+// rawText 原始数据为以回车或换行结束的卡号文本时返回卡号 (经串口转 HID 输出 ASCII 卡号的读卡器)
+func rawText(data []byte) (string, bool) {
+	end := len(data)
+	for end > 0 && (data[end-1] == '\r' || data[end-1] == '\n') {
+		end--
+	}
+	if end == len(data) || end == 0 {
+		return "", false
+	}
+	for _, c := range data[:end] {
+		if !('0' <= c && c <= '9' || 'A' <= c && c <= 'F' || 'a' <= c && c <= 'f') {
+			return "", false
+		}
+	}
+	return string(data[:end]), true
+}
+
+func containsKey(keys [6]byte, key byte) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
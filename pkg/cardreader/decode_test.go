@@ -0,0 +1,94 @@
+package cardreader
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// keyReport 按下一个键的键盘报告
+func keyReport(key byte) []byte {
+	return []byte{0, 0, key, 0, 0, 0, 0, 0}
+}
+
+func TestDecoderKeyboard(t *testing.T) {
+	d, err := NewDecoder(ModeAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// "12" + 回车，每个按键后跟一个松开的报告
+	release := make([]byte, keyboardReportLen)
+	for _, report := range [][]byte{keyReport(key1), release, keyReport(key1 + 1), release} {
+		if _, ok, err := d.Feed(report); ok || err != nil {
+			t.Fatalf("回车前 Feed = %v, %v", ok, err)
+		}
+	}
+	card, ok, err := d.Feed(keyReport(keyEnter))
+	if !ok || err != nil || card.Decimal() != "12" {
+		t.Fatalf("Feed(回车) = %s, %v, %v, 期望卡号 12", card, ok, err)
+	}
+	// 回车后松开的报告不产生卡号
+	d.Feed(release)
+	if _, ok, _ := d.Flush(); ok {
+		t.Error("松开回车后不应再读到卡号")
+	}
+}
+
+func TestDecoderRawSplit(t *testing.T) {
+	for _, mode := range []string{ModeAuto, ModeRaw} {
+		d, err := NewDecoder(mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// CH9325 格式: 一个 UID 分在两个报告中，第一个报告恰好也符合键盘报告格式
+		for _, report := range [][]byte{{0xF2, 0x00, 0x1E, 0, 0, 0, 0, 0}, {0xF2, 0x56, 0x78, 0, 0, 0, 0, 0}} {
+			if _, ok, err := d.Feed(report); ok || err != nil {
+				t.Fatalf("%s: 报告间隔结束前 Feed = %v, %v", mode, ok, err)
+			}
+		}
+		card, ok, err := d.Flush()
+		if !ok || err != nil || card.Hex() != "001E5678" {
+			t.Errorf("%s: Flush = %s, %v, %v, 期望 UID 001E5678", mode, card.Hex(), ok, err)
+		}
+	}
+}
+
+func TestDecoderRawText(t *testing.T) {
+	d, err := NewDecoder(ModeRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 卡号文本以回车换行结束，不需要等待报告间隔
+	if _, ok, _ := d.Feed([]byte{0xF4, '1', 'A', '2', 'B', 0, 0, 0}); ok {
+		t.Fatal("结束符前不应读到卡号")
+	}
+	card, ok, err := d.Feed([]byte{0xF6, '3', 'C', '4', 'D', '\r', '\n', 0})
+	if !ok || err != nil || card.Hex() != "1A2B3C4D" {
+		t.Fatalf("Feed = %s, %v, %v, 期望 UID 1A2B3C4D", card.Hex(), ok, err)
+	}
+	if d.Pending() {
+		t.Error("读到卡号后不应有缓存的报告")
+	}
+}
+
+func TestWatchSplitUID(t *testing.T) {
+	reports := make(chan readResult, reportQueueLen)
+	r := &Reader{isConnected: true, reports: reports}
+	d, err := NewDecoder(ModeAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports <- readResult{report: []byte{0xF2, 0x12, 0x34, 0, 0, 0, 0, 0}}
+	reports <- readResult{report: []byte{0xF2, 0x56, 0x78, 0, 0, 0, 0, 0}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*DefaultReportGap)
+	defer cancel()
+	var cards []string
+	if err := r.Watch(ctx, d, time.Second, func(card Card) { cards = append(cards, card.Hex()) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 1 || cards[0] != "12345678" {
+		t.Errorf("读到 %v, 期望一次 12345678", cards)
+	}
+}
@@ -0,0 +1,56 @@
+package cardreader
+
+import (
+	"math/bits"
+	"testing"
+)
+
+// 解析成功的卡号按十进制重新解析后数值不变，韦根 26 的校验位正确
+func FuzzParseCardNumber(f *testing.F) {
+	for _, s := range []string{"0012345678", "1A2B3C4D", "ABC", "18446744073709551615", "18446744073709551616", "XYZ", ""} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		card, err := ParseCardNumber(text)
+		if err != nil {
+			return
+		}
+		again, err := ParseCardNumber(card.Decimal())
+		if err != nil || again.Number() != card.Number() {
+			t.Fatalf("ParseCardNumber(%q) = %s, 十进制重新解析为 %v (%v)", text, card, again.Number(), err)
+		}
+
+		_, _, code := card.Wiegand26()
+		if bits.OnesCount32(code>>13)%2 != 0 || bits.OnesCount32(code&0x1FFF)%2 != 1 {
+			t.Fatalf("韦根 26 码 0x%07X 校验位错误", code)
+		}
+	})
+}
+
+// 任意报告序列都能被解码，读到的卡号不为空
+func FuzzDecoderFeed(f *testing.F) {
+	f.Add(byte(0), []byte{0, 0, 0x1E, 0, 0, 0, 0, 0, 0, 0, 0x28, 0, 0, 0, 0, 0})
+	f.Add(byte(1), []byte{0, 0, 0x04, 0x05, 0, 0, 0, 0, 0, 0, 0x2B, 0, 0, 0, 0, 0})
+	f.Add(byte(2), []byte{0xF4, 0x12, 0x34, 0x56, 0x78, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, mode byte, stream []byte) {
+		modes := []string{ModeAuto, ModeKeyboard, ModeRaw}
+		d, err := NewDecoder(modes[int(mode)%len(modes)])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for len(stream) > 0 {
+			n := min(keyboardReportLen, len(stream))
+			card, ok, err := d.Feed(stream[:n])
+			stream = stream[n:]
+			if err != nil {
+				continue
+			}
+			if ok && len(card.UID) == 0 {
+				t.Fatalf("读到空卡号: %+v", card)
+			}
+		}
+		if card, ok, err := d.Flush(); err == nil && ok && len(card.UID) == 0 {
+			t.Fatalf("Flush 读到空卡号: %+v", card)
+		}
+	})
+}
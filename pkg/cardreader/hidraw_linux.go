@@ -0,0 +1,105 @@
+package cardreader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/karalabe/hid"
+	"golang.org/x/sys/unix"
+)
+
+// hidraw ioctl 请求号, 见 linux/hidraw.h
+const (
+	iocWrite = 1
+	iocRead  = 2
+)
+
+func hidiocSFeature(n int) uintptr { return ioc(iocWrite|iocRead, 'H', 0x06, n) }
+func hidiocGFeature(n int) uintptr { return ioc(iocWrite|iocRead, 'H', 0x07, n) }
+
+func ioc(dir, typ, nr, size int) uintptr {
+	return uintptr(dir<<30 | size<<16 | typ<<8 | nr)
+}
+
+// hidrawPath 查找设备对应的 hidraw 节点
+//
+// hidapi 的 libusb 后端路径格式为 "总线:设备地址:接口" (十六进制)，
+// 通过 sysfs 找到同一总线、地址和接口的 hidraw 节点。
+func hidrawPath(info hid.DeviceInfo) (string, error) {
+	if strings.HasPrefix(info.Path, "/dev/hidraw") {
+		return info.Path, nil
+	}
+
+	var bus, addr, intf int
+	if _, err := fmt.Sscanf(info.Path, "%x:%x:%x", &bus, &addr, &intf); err != nil {
+		return "", fmt.Errorf("无法解析 HID 设备路径: %s", info.Path)
+	}
+
+	nodes, _ := filepath.Glob("/sys/class/hidraw/hidraw*")
+	for _, node := range nodes {
+		// .../<USB 设备>/<USB 接口>/<HID 设备>/hidraw/hidrawN
+		hidDev, err := filepath.EvalSymlinks(filepath.Join(node, "device"))
+		if err != nil {
+			continue
+		}
+		usbIntf := filepath.Dir(hidDev)
+		usbDev := filepath.Dir(usbIntf)
+		if readSysInt(filepath.Join(usbDev, "busnum"), 10) != bus ||
+			readSysInt(filepath.Join(usbDev, "devnum"), 10) != addr ||
+			readSysInt(filepath.Join(usbIntf, "bInterfaceNumber"), 16) != intf {
+			continue
+		}
+		return "/dev/" + filepath.Base(node), nil
+	}
+	return "", fmt.Errorf("未找到 HID 设备 %s 对应的 hidraw 节点", info.Path)
+}
+
+// readSysInt 读取 sysfs 中的整数属性，失败时返回 -1
+func readSysInt(path string, base int) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return -1
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(string(data)), base, 32)
+	if err != nil {
+		return -1
+	}
+	return int(n)
+}
+
+// sendFeatureReport 发送特性报告 (首字节为报告 ID)
+func sendFeatureReport(path string, report []byte) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := append([]byte(nil), report...)
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), hidiocSFeature(len(buf)), uintptr(unsafe.Pointer(&buf[0])))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// getFeatureReport 读取特性报告，返回的报告首字节为报告 ID
+func getFeatureReport(path string, id byte, length int) ([]byte, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, length)
+	buf[0] = id
+	n, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), hidiocGFeature(len(buf)), uintptr(unsafe.Pointer(&buf[0])))
+	if errno != 0 {
+		return nil, errno
+	}
+	return buf[:n], nil
+}
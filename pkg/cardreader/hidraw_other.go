@@ -0,0 +1,17 @@
+//go:build !linux
+
+package cardreader
+
+import "github.com/karalabe/hid"
+
+func hidrawPath(info hid.DeviceInfo) (string, error) {
+	return "", errFeatureUnsupported
+}
+
+func sendFeatureReport(path string, report []byte) error {
+	return errFeatureUnsupported
+}
+
+func getFeatureReport(path string, id byte, length int) ([]byte, error) {
+	return nil, errFeatureUnsupported
+}
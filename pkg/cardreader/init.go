@@ -0,0 +1,66 @@
+package cardreader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/karalabe/hid"
+)
+
+// DefaultBaudRate 读卡模块的串口波特率
+//
+// 读卡器由读卡模块和 CH9325 串口转 HID 芯片组成，上电后需要先用特性报告
+// 设置芯片的串口参数，读卡模块的数据才会以输入报告上传。
+const DefaultBaudRate = 9600
+
+// 串口参数特性报告: 报告 ID(1) + 波特率(2, 小端) + 保留(1) + 数据格式(1)
+const (
+	featureReportID  = 0x00
+	featureReportLen = 5
+	uartFormat8N1    = 0x03 // 8 数据位, 无校验, 1 停止位
+)
+
+// errFeatureUnsupported 当前平台不支持发送特性报告
+var errFeatureUnsupported = errors.New("当前平台不支持 HID 特性报告")
+
+// InitError 读卡器初始化确认失败
+type InitError struct {
+	Sent []byte // 发送的特性报告
+	Got  []byte // 读回的特性报告
+}
+
+func (e *InitError) Error() string {
+	return fmt.Sprintf("读卡器初始化确认失败: 发送 % X, 读回 % X", e.Sent, e.Got)
+}
+
+// initReport 生成设置串口参数的特性报告
+func initReport(baudRate int) []byte {
+	return []byte{featureReportID, byte(baudRate), byte(baudRate >> 8), 0x00, uartFormat8N1}
+}
+
+// initialize 发送初始化特性报告并读回确认，返回读回的报告
+//
+// 必须在 Open 之前调用: Linux 上 HID 库通过 libusb 打开设备时会卸载内核驱动，
+// 之后 hidraw 节点不再可用。
+func initialize(info hid.DeviceInfo, baudRate int) ([]byte, error) {
+	path, err := hidrawPath(info)
+	if err != nil {
+		return nil, err
+	}
+
+	report := initReport(baudRate)
+	if err := sendFeatureReport(path, report); err != nil {
+		return nil, fmt.Errorf("发送初始化特性报告失败 (%s): %w", path, err)
+	}
+
+	ack, err := getFeatureReport(path, featureReportID, featureReportLen)
+	if err != nil {
+		return nil, fmt.Errorf("读取初始化确认失败 (%s): %w", path, err)
+	}
+	// 读回的报告应与设置的串口参数一致 (报告 ID 之后的字节)
+	if len(ack) < featureReportLen || !bytes.Equal(ack[1:featureReportLen], report[1:]) {
+		return ack, &InitError{Sent: report, Got: ack}
+	}
+	return ack, nil
+}
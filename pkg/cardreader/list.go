@@ -0,0 +1,94 @@
+package cardreader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/karalabe/hid"
+)
+
+// DeviceInfo 枚举到的 HID 设备
+type DeviceInfo struct {
+	VID          int
+	PID          int
+	Path         string // HID 库的设备路径 (Linux libusb 后端为 "总线:地址:接口")
+	Hidraw       string // 对应的 hidraw 节点，找不到时为空
+	Serial       string // USB 序列号
+	Interface    int    // USB 接口号
+	Manufacturer string
+	Product      string
+
+	info hid.DeviceInfo
+}
+
+// String 返回设备的可读描述
+func (d DeviceInfo) String() string {
+	s := fmt.Sprintf("%04X:%04X 路径 %s", d.VID, d.PID, d.Path)
+	if d.Hidraw != "" {
+		s += " (" + d.Hidraw + ")"
+	}
+	s += fmt.Sprintf(", 接口 %d", d.Interface)
+	if d.Serial != "" {
+		s += ", 序列号 " + d.Serial
+	}
+	if d.Product != "" || d.Manufacturer != "" {
+		s += fmt.Sprintf(", %s - %s", d.Product, d.Manufacturer)
+	}
+	return s
+}
+
+// Matches 判断设备是否与选择条件匹配: 序列号、hidraw 节点或 HID 路径
+func (d DeviceInfo) Matches(selector string) bool {
+	if selector == "" {
+		return true
+	}
+	return (d.Serial != "" && d.Serial == selector) ||
+		(d.Hidraw != "" && (d.Hidraw == selector || strings.TrimPrefix(d.Hidraw, "/dev/") == selector)) ||
+		d.Path == selector
+}
+
+// List 枚举指定 VID/PID 的 HID 设备，VID 和 PID 为 0 时列出所有设备
+func List(vid, pid int) []DeviceInfo {
+	// 枚举失败 (如不支持的平台) 与没有设备相同，返回空列表
+	infos, _ := hid.Enumerate(uint16(vid), uint16(pid))
+	var devices []DeviceInfo
+	for _, info := range infos {
+		hidraw, _ := hidrawPath(info)
+		devices = append(devices, DeviceInfo{
+			VID:          int(info.VendorID),
+			PID:          int(info.ProductID),
+			Path:         info.Path,
+			Hidraw:       hidraw,
+			Serial:       info.Serial,
+			Interface:    info.Interface,
+			Manufacturer: info.Manufacturer,
+			Product:      info.Product,
+			info:         info,
+		})
+	}
+	return devices
+}
+
+// selectDevice 按选择条件从枚举结果中选出一个设备
+//
+// 未指定选择条件时使用第一个设备；指定了条件但有多个设备匹配时报错，避免测试错设备。
+func selectDevice(devices []DeviceInfo, selector string) (DeviceInfo, error) {
+	var matched []DeviceInfo
+	for _, d := range devices {
+		if d.Matches(selector) {
+			matched = append(matched, d)
+		}
+	}
+
+	switch {
+	case len(matched) == 0:
+		available := make([]string, 0, len(devices))
+		for _, d := range devices {
+			available = append(available, d.String())
+		}
+		return DeviceInfo{}, fmt.Errorf("没有与 %q 匹配的读卡器，可用设备: %s", selector, strings.Join(available, "; "))
+	case len(matched) > 1 && selector != "":
+		return DeviceInfo{}, fmt.Errorf("有 %d 个读卡器与 %q 匹配，请使用 hidraw 节点区分", len(matched), selector)
+	}
+	return matched[0], nil
+}
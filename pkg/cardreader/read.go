@@ -0,0 +1,94 @@
+package cardreader
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// reportSize HID 报告缓冲区大小
+const reportSize = 64
+
+// reportQueueLen 未被取走的报告最多缓存的个数，缓存满时读取协程等待
+const reportQueueLen = 16
+
+// readPollInterval 读取协程每次读取的超时，超时后检查是否需要退出
+const readPollInterval = 100 * time.Millisecond
+
+// timeoutReader 支持超时读取的设备，timeout 为毫秒，超时时返回 0 字节
+type timeoutReader interface {
+	ReadTimeout(b []byte, timeout int) (int, error)
+}
+
+// readResult 后台读取的一个报告
+type readResult struct {
+	report []byte
+	err    error
+}
+
+// startReading 启动设备的读取协程
+func (r *Reader) startReading() {
+	reports := make(chan readResult, reportQueueLen)
+	r.reports = reports
+	r.stop = make(chan struct{})
+	r.readerDone = make(chan struct{})
+	go readLoop(r.device, reports, r.stop, r.readerDone)
+}
+
+// readLoop 持续读取报告直到出错或 stop 关闭
+//
+// 每次读取最多等待 readPollInterval，两次读取之间检查 stop，所以 Disconnect 关闭 stop 后
+// 可以等待本协程退出再关闭设备，不会在读取阻塞时释放设备句柄。
+// 每次读取使用新的缓冲区，取走的报告不会被后续读取覆盖。
+func readLoop(dev timeoutReader, reports chan<- readResult, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	defer close(reports)
+
+	timeout := int(readPollInterval / time.Millisecond)
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		buf := make([]byte, reportSize)
+		n, err := dev.ReadTimeout(buf, timeout)
+		if err == nil && n == 0 {
+			continue // 超时，没有新的报告
+		}
+		res := readResult{report: buf[:n]}
+		if err != nil {
+			res = readResult{err: fmt.Errorf("读取数据失败: %w", err)}
+		}
+
+		select {
+		case reports <- res:
+		case <-stop:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// ReadContext 读取一个 HID 报告，ctx 取消或超时时返回 ctx.Err()
+//
+// 报告由后台读取协程送来，取消等待不会留下阻塞的读取，
+// 未取走的报告留给下一次读取。
+func (r *Reader) ReadContext(ctx context.Context) ([]byte, error) {
+	if !r.isConnected {
+		return nil, fmt.Errorf("设备未连接")
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res, ok := <-r.reports:
+		if !ok {
+			return nil, fmt.Errorf("设备读取已停止")
+		}
+		return res.report, res.err
+	}
+}
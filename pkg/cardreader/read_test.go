@@ -0,0 +1,42 @@
+package cardreader
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// idleDevice 没有刷卡的设备，每次读取等到超时后返回 0 字节
+type idleDevice struct {
+	reading atomic.Int32
+}
+
+func (d *idleDevice) ReadTimeout(b []byte, timeout int) (int, error) {
+	d.reading.Add(1)
+	defer d.reading.Add(-1)
+	time.Sleep(time.Duration(timeout) * time.Millisecond)
+	return 0, nil
+}
+
+func TestReadLoopStop(t *testing.T) {
+	dev := &idleDevice{}
+	reports := make(chan readResult, reportQueueLen)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go readLoop(dev, reports, stop, done)
+
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(3 * readPollInterval):
+		t.Fatal("关闭 stop 后读取协程没有退出")
+	}
+	// 协程退出后才能关闭设备，此时不能有读取仍在进行
+	if n := dev.reading.Load(); n != 0 {
+		t.Errorf("读取协程退出时仍有 %d 个读取在进行", n)
+	}
+	if _, ok := <-reports; ok {
+		t.Error("超时读取不应送出报告")
+	}
+}
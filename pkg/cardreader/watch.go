@@ -0,0 +1,59 @@
+package cardreader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultHoldoff 同一张卡在读卡器上停留时重复上报的抑制时间
+const DefaultHoldoff = time.Second
+
+// Watch 持续读取刷卡，直到 ctx 取消
+//
+// 同一张卡在 holdoff 时间内重复读到时不再上报 (卡片一直放在读卡器上时，
+// 读卡器会不断重复输出卡号)，卡片离开超过 holdoff 后再次刷卡会重新上报。
+// 解码器缓存了报告时，超过 DefaultReportGap 没有新报告就调用 Flush 结束这次读卡。
+func (r *Reader) Watch(ctx context.Context, dec *Decoder, holdoff time.Duration, onCard func(Card)) error {
+	if !r.isConnected {
+		return fmt.Errorf("设备未连接")
+	}
+
+	var last Card
+	var lastSeen time.Time
+	for {
+		readCtx, cancel := ctx, context.CancelFunc(func() {})
+		if dec.Pending() {
+			readCtx, cancel = context.WithTimeout(ctx, DefaultReportGap)
+		}
+		report, err := r.ReadContext(readCtx)
+		cancel()
+
+		var card Card
+		var ok bool
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return nil
+		case errors.Is(err, context.DeadlineExceeded):
+			card, ok, err = dec.Flush()
+		case err != nil:
+			return err
+		default:
+			card, ok, err = dec.Feed(report)
+		}
+		if err != nil {
+			dec.Reset()
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if card.Equal(last) && card.Time.Sub(lastSeen) < holdoff {
+			lastSeen = card.Time
+			continue
+		}
+		last, lastSeen = card, card.Time
+		onCard(card)
+	}
+}
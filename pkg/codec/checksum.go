@@ -0,0 +1,28 @@
+package codec
+
+// XOR 计算所有字节的异或校验 (锁控板协议)
+func XOR(data []byte) byte {
+	var checksum byte
+	for _, b := range data {
+		checksum ^= b
+	}
+	return checksum
+}
+
+// CRC16 计算 CRC-16/CCITT (多项式 0x1021, 初始值 0xFFFF，RFID 读写器协议)
+//
+// 参数沿用最初实现 (calculateCRC)，尚未用读写器协议文档或抓包核对。
+func CRC16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = (crc << 1) ^ 0x1021
+			} else {
+				crc = crc << 1
+			}
+		}
+	}
+	return crc
+}
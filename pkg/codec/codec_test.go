@@ -0,0 +1,135 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestDecodeHex(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []byte
+	}{
+		{"", []byte{}},
+		{"80010033", []byte{0x80, 0x01, 0x00, 0x33}},
+		{"8a0bFf", []byte{0x8A, 0x0B, 0xFF}},
+	}
+	for _, tt := range tests {
+		got, err := DecodeHex(tt.in)
+		if err != nil || !bytes.Equal(got, tt.want) {
+			t.Errorf("DecodeHex(%q) = %X, %v, 期望 %X", tt.in, got, err, tt.want)
+		}
+	}
+
+	if _, err := DecodeHex("800"); !errors.Is(err, ErrOddLength) {
+		t.Errorf("错误 = %v, 期望 ErrOddLength", err)
+	}
+	for in, pos := range map[string]int{"8G": 1, "80  01": 2, "0x80": 1, "８０": 0} {
+		var hexErr *InvalidHexError
+		if _, err := DecodeHex(in); !errors.As(err, &hexErr) || hexErr.Pos != pos {
+			t.Errorf("DecodeHex(%q) 错误 = %v, 期望位置 %d 的 *InvalidHexError", in, err, pos)
+		}
+	}
+}
+
+func TestParseHex(t *testing.T) {
+	want := []byte{0x80, 0x01, 0x00, 0x33}
+	for _, in := range []string{"80010033", "80 01 00 33", "0x80,0x01,0x00,0x33", "80:01:00:33", " 8001\t0033\n", "80-01-00-33"} {
+		got, err := ParseHex(in)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("ParseHex(%q) = %X, %v, 期望 %X", in, got, err, want)
+		}
+	}
+	// 奇数长度的段不能与相邻的段拼成字节
+	for _, in := range []string{"8 0 1", "80 0G", "x80", "0x800", "8 01 0 33", "0x8 0x1", "80 1", "0x", "80,,0x"} {
+		if got, err := ParseHex(in); err == nil {
+			t.Errorf("ParseHex(%q) = %X, 期望返回错误", in, got)
+		}
+	}
+
+	// 非法字符的位置相对于整个输入
+	var invalid *InvalidHexError
+	if _, err := ParseHex("80 0x0G"); !errors.As(err, &invalid) || invalid.Pos != 6 || invalid.Char != 'G' {
+		t.Errorf("错误 = %v, 期望位置 6 的 'G'", err)
+	}
+}
+
+func TestHexByte(t *testing.T) {
+	if s, err := HexByte("板地址", 10); err != nil || s != "0A" {
+		t.Errorf("HexByte(10) = %q, %v", s, err)
+	}
+	for _, v := range []int{-1, 256, 300} {
+		if s, err := HexByte("板地址", v); err == nil {
+			t.Errorf("HexByte(%d) = %q, 期望返回错误", v, s)
+		}
+	}
+}
+
+func TestChecksums(t *testing.T) {
+	if got := XOR([]byte{0x80, 0x01, 0x00, 0x33}); got != 0xB2 {
+		t.Errorf("XOR = 0x%02X, 期望 0xB2", got)
+	}
+	if got := CRC16([]byte{0x5A, 0x00, 0x01, 0x02, 0xFF, 0x00, 0x00}); got != 0x604D {
+		t.Errorf("CRC16 = 0x%04X, 期望 0x604D", got)
+	}
+}
+
+// DecodeHex 与标准库接受和拒绝的输入完全一致
+func FuzzDecodeHex(f *testing.F) {
+	for _, s := range []string{"", "80010033B2", "8a0bff", "800", "8G", "80 01", "\xff\xfe"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		got, err := DecodeHex(s)
+		want, stdErr := hex.DecodeString(s)
+		if (err == nil) != (stdErr == nil) {
+			t.Fatalf("DecodeHex(%q) 错误 = %v, 标准库错误 = %v", s, err, stdErr)
+		}
+		if err != nil {
+			return
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("DecodeHex(%q) = %X, 期望 %X", s, got, want)
+		}
+		if again, err := DecodeHex(EncodeHex(got)); err != nil || !bytes.Equal(again, got) {
+			t.Fatalf("EncodeHex 往返失败: %X -> %q", got, EncodeHex(got))
+		}
+	})
+}
+
+// ParseHex 解码成功时重新编码再解析得到相同的结果
+func FuzzParseHex(f *testing.F) {
+	for _, s := range []string{"80 01 00 33", "0x80,0x01", "80:01", "8 0", "0x0x80", "8 01 0 33", "0x8 0x1"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		data, err := ParseHex(s)
+		if err != nil {
+			return
+		}
+		if again, err := ParseHex(EncodeHex(data)); err != nil || !bytes.Equal(again, data) {
+			t.Fatalf("ParseHex(%q) = %X, 重新解析为 %X (%v)", s, data, again, err)
+		}
+	})
+}
+
+func FuzzHexByte(f *testing.F) {
+	for _, v := range []int{0, 1, 255, 256, -1, 4096} {
+		f.Add(v)
+	}
+	f.Fuzz(func(t *testing.T, v int) {
+		s, err := HexByte("值", v)
+		if (err == nil) != (v >= 0 && v <= 0xFF) {
+			t.Fatalf("HexByte(%d) = %q, %v", v, s, err)
+		}
+		if err != nil {
+			return
+		}
+		b, err := DecodeHex(s)
+		if err != nil || len(b) != 1 || int(b[0]) != v {
+			t.Fatalf("HexByte(%d) = %q 无法解码为原值", v, s)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+// Package codec 提供各设备协议共用的十六进制编解码和校验算法
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrOddLength 十六进制字符串长度为奇数
+var ErrOddLength = errors.New("十六进制字符串长度为奇数")
+
+// InvalidHexError 十六进制字符串中含有非法字符
+type InvalidHexError struct {
+	Pos  int  // 字符位置 (从 0 开始)
+	Char byte // 非法字符
+}
+
+func (e *InvalidHexError) Error() string {
+	return fmt.Sprintf("非法的十六进制字符 %q (位置 %d)", e.Char, e.Pos)
+}
+
+// DecodeHex 将十六进制字符串严格解码为字节，大小写均可
+//
+// 长度为奇数或含有非十六进制字符 (包括空格) 时返回错误，不会生成部分正确的数据。
+func DecodeHex(s string) ([]byte, error) {
+	if len(s)%2 != 0 {
+		return nil, fmt.Errorf("%w: %d", ErrOddLength, len(s))
+	}
+	data := make([]byte, len(s)/2)
+	for i := 0; i < len(s); i += 2 {
+		hi, ok := fromHexChar(s[i])
+		if !ok {
+			return nil, &InvalidHexError{Pos: i, Char: s[i]}
+		}
+		lo, ok := fromHexChar(s[i+1])
+		if !ok {
+			return nil, &InvalidHexError{Pos: i + 1, Char: s[i+1]}
+		}
+		data[i/2] = hi<<4 | lo
+	}
+	return data, nil
+}
+
+// ParseHex 解析手工输入的十六进制，如 "80 01 00 33"、"0x80,0x01" 或 "80:01:00:33"
+//
+// 空白、逗号、冒号和短横线视为分隔符，每段可带 0x 前缀。每段单独按 DecodeHex 严格解码，
+// 奇数长度的段 (如 "8 01" 中的 "8") 返回错误，不会与相邻的段拼成一个字节。
+func ParseHex(s string) ([]byte, error) {
+	var data []byte
+	for i := 0; i < len(s); {
+		if isHexSeparator(s[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(s) && !isHexSeparator(s[i]) {
+			i++
+		}
+
+		field, pos := s[start:i], start
+		if strings.HasPrefix(field, "0x") || strings.HasPrefix(field, "0X") {
+			field, pos = field[2:], pos+2
+		}
+		if field == "" {
+			return nil, fmt.Errorf("无效的十六进制 %q: 0x 之后没有数字 (位置 %d)", s, start)
+		}
+		b, err := DecodeHex(field)
+		if err != nil {
+			var invalid *InvalidHexError
+			if errors.As(err, &invalid) {
+				err = &InvalidHexError{Pos: pos + invalid.Pos, Char: invalid.Char}
+			} else {
+				err = fmt.Errorf("%w: %q (位置 %d)", ErrOddLength, s[start:i], start)
+			}
+			return nil, fmt.Errorf("无效的十六进制 %q: %w", s, err)
+		}
+		data = append(data, b...)
+	}
+	return data, nil
+}
+
+// isHexSeparator 判断是否为 ParseHex 接受的分隔符
+func isHexSeparator(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', ',', ':', '-':
+		return true
+	}
+	return false
+}
+
+// EncodeHex 将字节编码为大写十六进制字符串，与设备文档和参考代码的写法一致
+func EncodeHex(data []byte) string {
+	return fmt.Sprintf("%X", data)
+}
+
+// HexByte 将 0-255 的整数编码为两位十六进制，超出范围时返回错误
+//
+// fmt.Sprintf("%02X") 对超出范围的值会生成三位或更多字符，拼接到命令中会使整个帧错位。
+func HexByte(name string, v int) (string, error) {
+	if v < 0 || v > 0xFF {
+		return "", fmt.Errorf("%s超出范围: %d (应为 0-255)", name, v)
+	}
+	return fmt.Sprintf("%02X", v), nil
+}
+
+// fromHexChar 解析一个十六进制字符
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	}
+	return 0, false
+}
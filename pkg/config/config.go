@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config 硬件测试配置 (对应 config.toml)
+type Config struct {
+	RFID       RFIDConfig       `toml:"rfid"`
+	Lock       LockConfig       `toml:"lock"`
+	Screen     ScreenConfig     `toml:"screen"`
+	CardReader CardReaderConfig `toml:"cardreader"`
+}
+
+// 连接类型
+const (
+	TypeSerial = "serial"
+	TypeSocket = "socket"
+)
+
+// Endpoint 设备连接端点，每个设备使用各自的端点
+type Endpoint struct {
+	Type       string `toml:"type"`
+	Host       string `toml:"host"`
+	Port       int    `toml:"port"`
+	SerialPort string `toml:"serial_port"`
+	BaudRate   int    `toml:"baud_rate"`
+}
+
+// IsSocket 是否为 Socket 连接
+func (e Endpoint) IsSocket() bool {
+	return e.Type == TypeSocket
+}
+
+// Configured 端点是否已配置连接参数
+func (e Endpoint) Configured() bool {
+	if e.IsSocket() {
+		return e.Host != "" && e.Port > 0
+	}
+	return e.SerialPort != ""
+}
+
+// String 返回端点的可读描述
+func (e Endpoint) String() string {
+	if e.IsSocket() {
+		return fmt.Sprintf("socket://%s:%d", e.Host, e.Port)
+	}
+	return fmt.Sprintf("serial://%s@%d", e.SerialPort, e.BaudRate)
+}
+
+// Validate 检查端点参数
+func (e Endpoint) Validate() error {
+	switch e.Type {
+	case TypeSocket:
+		if e.Host == "" || e.Port <= 0 || e.Port > 65535 {
+			return fmt.Errorf("Socket 连接需要有效的 host 和 port")
+		}
+	case TypeSerial:
+		if e.SerialPort == "" {
+			return fmt.Errorf("串口连接需要 serial_port")
+		}
+		if e.BaudRate <= 0 {
+			return fmt.Errorf("无效的波特率: %d", e.BaudRate)
+		}
+	default:
+		return fmt.Errorf("未知连接类型: %q (应为 \"serial\" 或 \"socket\")", e.Type)
+	}
+	return nil
+}
+
+// RFIDConfig RFID 读写器配置
+type RFIDConfig struct {
+	Endpoint
+	Antennas []int `toml:"antennas"`
+}
+
+// LockConfig 锁控板配置
+type LockConfig struct {
+	Endpoint
+}
+
+// ScreenConfig 串口屏配置
+type ScreenConfig struct {
+	Endpoint
+	Encoding   string `toml:"encoding"`   // 文本字符集: gbk 或 gb18030
+	Substitute string `toml:"substitute"` // 无法编码的字符的替换字符，为空时报错
+	Verify     string `toml:"verify"`     // 连接测试时写入并读回的数值变量，如 sys0 或 n0.val
+}
+
+// CardReaderConfig 读卡器配置
+type CardReaderConfig struct {
+	VID      int    `toml:"vid"`
+	PID      int    `toml:"pid"`
+	Mode     string `toml:"mode"`      // 报告解析模式: auto, keyboard 或 raw
+	BaudRate int    `toml:"baud_rate"` // 初始化时设置的读卡模块串口波特率
+	Device   string `toml:"device"`    // 多个读卡器时按序列号或 hidraw 节点选择
+}
+
+// String 返回读卡器的端点描述，如 hid://1A86:E000 或 hid://1A86:E000/hidraw1
+func (c CardReaderConfig) String() string {
+	s := fmt.Sprintf("hid://%04X:%04X", c.VID, c.PID)
+	if c.Device != "" {
+		s += "/" + strings.TrimPrefix(c.Device, "/dev/")
+	}
+	return s
+}
+
+// Default 返回默认配置，锁控板和串口屏默认使用不同的串口
+func Default() *Config {
+	return &Config{
+		RFID: RFIDConfig{
+			Endpoint: Endpoint{Type: TypeSocket},
+			Antennas: []int{1, 2, 3, 4},
+		},
+		Lock: LockConfig{
+			Endpoint: Endpoint{Type: TypeSerial, SerialPort: "/dev/ttyS0", BaudRate: 115200},
+		},
+		Screen: ScreenConfig{
+			Endpoint: Endpoint{Type: TypeSerial, SerialPort: "/dev/ttyS1", BaudRate: 115200},
+			Encoding: "gbk",
+			Verify:   "sys0",
+		},
+		CardReader: CardReaderConfig{
+			VID:      0x1A86,
+			PID:      0xE000,
+			Mode:     "auto",
+			BaudRate: 9600,
+		},
+	}
+}
+
+// Load 加载配置文件，文件中未出现的字段保留默认值
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if _, err := toml.DecodeFile(path, cfg); err != nil {
+		return nil, fmt.Errorf("加载配置文件 %s 失败: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 %s 无效: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate 检查配置是否有效
+func (c *Config) Validate() error {
+	if err := validateType("rfid", c.RFID.Type); err != nil {
+		return err
+	}
+	if err := validateType("lock", c.Lock.Type); err != nil {
+		return err
+	}
+	if err := validateType("screen", c.Screen.Type); err != nil {
+		return err
+	}
+	for _, ant := range c.RFID.Antennas {
+		if ant < 1 || ant > 32 {
+			return fmt.Errorf("[rfid] 天线编号超出范围 (1-32): %d", ant)
+		}
+	}
+	return nil
+}
+
+// CheckDistinct 检查一起测试的 rfid、lock、screen 没有共用同一个串口或 Socket 地址
+func (c *Config) CheckDistinct(modules []string) error {
+	seen := make(map[string]string)
+	for _, m := range modules {
+		var ep Endpoint
+		switch m {
+		case "rfid":
+			ep = c.RFID.Endpoint
+		case "lock":
+			ep = c.Lock.Endpoint
+		case "screen":
+			ep = c.Screen.Endpoint
+		default:
+			continue
+		}
+		addr := ep.address()
+		if other, ok := seen[addr]; ok {
+			return fmt.Errorf("[%s] 和 [%s] 使用了同一个端点 %s，请分别设置", other, m, addr)
+		}
+		seen[addr] = m
+	}
+	return nil
+}
+
+// address 返回端点的连接地址 (不含波特率)，用于判断两个端点是否指向同一设备
+func (e Endpoint) address() string {
+	if e.IsSocket() {
+		return fmt.Sprintf("socket://%s:%d", e.Host, e.Port)
+	}
+	return "serial://" + e.SerialPort
+}
+
+// validateType 检查连接类型
+func validateType(section, connType string) error {
+	switch connType {
+	case TypeSerial, TypeSocket:
+		return nil
+	default:
+		return fmt.Errorf("[%s] 未知连接类型: %q (应为 \"serial\" 或 \"socket\")", section, connType)
+	}
+}
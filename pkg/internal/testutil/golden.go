@@ -0,0 +1,51 @@
+package testutil
+
+import (
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Frame 黄金样例文件中的一帧
+type Frame struct {
+	Name   string // 帧名称，测试按名称找到生成该帧的代码
+	Source string // 帧的来源，比对失败时输出
+	Bytes  []byte
+}
+
+// Golden 读取黄金样例文件
+//
+// 每行为 "名称 | 来源 | 十六进制帧"，十六进制中的空格会被忽略；空行和 # 开头的行为注释。
+func Golden(t testing.TB, path string) []Frame {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var frames []Frame
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "|")
+		if len(fields) != 3 {
+			t.Fatalf("%s:%d: 应为 \"名称 | 来源 | 帧\": %q", path, i+1, line)
+		}
+		b, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(fields[2]), " ", ""))
+		if err != nil {
+			t.Fatalf("%s:%d: %v", path, i+1, err)
+		}
+		frames = append(frames, Frame{
+			Name:   strings.TrimSpace(fields[0]),
+			Source: strings.TrimSpace(fields[1]),
+			Bytes:  b,
+		})
+	}
+	if len(frames) == 0 {
+		t.Fatalf("%s: 没有样例", path)
+	}
+	return frames
+}
@@ -0,0 +1,101 @@
+// Package testutil 提供锁控板、RFID、屏幕测试共用的辅助函数
+//
+// 不依赖 lock、rfid、screen 和 simulator，包内测试 (package lock 等) 也可以导入。
+package testutil
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"hardware-test/pkg/transport"
+)
+
+// MustHex 解码十六进制字符串，失败时终止测试
+func MustHex(t testing.TB, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// Device 模拟设备，与 simulator.Device 相同
+type Device interface {
+	Serve(rw io.ReadWriter) error
+}
+
+// Dial 在本地随机端口上启动模拟设备，返回连接到它的 Dialer
+//
+// 测试结束时关闭监听和所有连接，并等待设备的 Serve 返回。
+func Dial(t testing.TB, dev Device) transport.Dialer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu    sync.Mutex
+		conns []net.Conn
+		wg    sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if err != nil {
+				continue
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				dev.Serve(conn)
+				conn.Close()
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		ln.Close()
+		mu.Lock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+		mu.Unlock()
+		wg.Wait()
+	})
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	return func() (transport.Transport, error) {
+		return transport.DialTCP("127.0.0.1", port, time.Second)
+	}
+}
+
+// Conn 可以连接和断开的控制器，如 *lock.Controller、*rfid.Reader、*screen.Controller
+type Conn interface {
+	Connect() error
+	Disconnect() error
+}
+
+// Connect 连接控制器并在测试结束时断开，连接失败时终止测试
+func Connect[C Conn](t testing.TB, c C) C {
+	t.Helper()
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Disconnect() })
+	return c
+}
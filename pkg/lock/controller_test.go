@@ -0,0 +1,115 @@
+package lock_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"hardware-test/pkg/internal/testutil"
+	"hardware-test/pkg/lock"
+	"hardware-test/pkg/simulator"
+	"hardware-test/pkg/transport"
+)
+
+func TestControllerQueryStatus(t *testing.T) {
+	boards := simulator.NewLockBoards([]int{1, 2}, 16)
+	boards.SetOpen(2, 5, true)
+	c := lock.NewControllerWithDialer(testutil.Dial(t, boards))
+	c.SetResponseTimeout(200 * time.Millisecond)
+	testutil.Connect(t, c)
+
+	status, err := c.QueryStatus(2)
+	if err != nil {
+		t.Fatalf("QueryStatus: %v", err)
+	}
+	if status.BoardAddr != 2 || len(status.Locks) != 16 {
+		t.Errorf("状态 = %s", status)
+	}
+	if open := status.OpenLocks(); len(open) != 1 || open[0] != 5 {
+		t.Errorf("打开的锁 = %v, 期望 [5]", open)
+	}
+
+	if _, err := c.QueryStatus(3); err == nil {
+		t.Error("不存在的板地址应返回错误")
+	}
+}
+
+func TestControllerOpen(t *testing.T) {
+	boards := simulator.NewLockBoards([]int{1}, 16)
+	c := lock.NewControllerWithDialer(testutil.Dial(t, boards))
+	c.SetResponseTimeout(200 * time.Millisecond)
+	testutil.Connect(t, c)
+
+	if err := c.Open(1, 3); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	// 开锁应答在下一次查询前被丢弃，查询结果应反映开锁
+	status, err := c.QueryStatus(1)
+	if err != nil {
+		t.Fatalf("QueryStatus: %v", err)
+	}
+	if l, _ := status.Lock(3); !l.Open {
+		t.Errorf("3 号锁 = %s, 期望 OPEN", l)
+	}
+	if !boards.IsOpen(1, 3) {
+		t.Error("模拟锁控板的 3 号锁未打开")
+	}
+}
+
+func TestControllerQueryAll(t *testing.T) {
+	c := lock.NewControllerWithDialer(testutil.Dial(t, simulator.NewLockBoards([]int{1, 3}, 8)))
+	c.SetResponseTimeout(200 * time.Millisecond)
+	testutil.Connect(t, c)
+
+	all, err := c.QueryAll()
+	if err != nil {
+		t.Fatalf("QueryAll: %v", err)
+	}
+	if len(all) != 2 || all[0].BoardAddr != 1 || all[1].BoardAddr != 3 {
+		t.Fatalf("QueryAll 返回 %d 块板: %+v", len(all), all)
+	}
+	for _, s := range all {
+		if s.ParseErr != nil || s.Board == nil || len(s.Board.Locks) != 8 {
+			t.Errorf("板 %d: %+v", s.BoardAddr, s)
+		}
+	}
+}
+
+func TestControllerFaults(t *testing.T) {
+	t.Run("校验错误", func(t *testing.T) {
+		boards := simulator.NewLockBoards([]int{1}, 16)
+		boards.SetFaults(simulator.Faults{CorruptRate: 1})
+		c := lock.NewControllerWithDialer(testutil.Dial(t, boards))
+		c.SetResponseTimeout(200 * time.Millisecond)
+		testutil.Connect(t, c)
+
+		var checksumErr *lock.ChecksumError
+		if _, err := c.QueryStatus(1); !errors.As(err, &checksumErr) {
+			t.Errorf("错误 = %v, 期望 *ChecksumError", err)
+		}
+	})
+
+	t.Run("无应答", func(t *testing.T) {
+		boards := simulator.NewLockBoards([]int{1}, 16)
+		boards.SetFaults(simulator.Faults{DropRate: 1})
+		c := lock.NewControllerWithDialer(testutil.Dial(t, boards))
+		c.SetResponseTimeout(200 * time.Millisecond)
+		testutil.Connect(t, c)
+
+		if _, err := c.Probe(); !transport.IsTimeout(err) {
+			t.Errorf("错误 = %v, 期望超时", err)
+		}
+	})
+
+	t.Run("延迟", func(t *testing.T) {
+		boards := simulator.NewLockBoards([]int{1}, 16)
+		boards.SetFaults(simulator.Faults{Delay: 50 * time.Millisecond})
+		c := lock.NewControllerWithDialer(testutil.Dial(t, boards))
+		c.SetResponseTimeout(200 * time.Millisecond)
+		testutil.Connect(t, c)
+
+		if _, err := c.Probe(); err != nil {
+			t.Errorf("Probe: %v", err)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package lock
+
+import (
+	"fmt"
+	"io"
+
+	"hardware-test/pkg/codec"
+)
+
+// ChecksumError 响应帧异或校验错误
+type ChecksumError struct {
+	Want byte
+	Got  byte
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("校验失败: 期望 0x%02X, 实际 0x%02X", e.Want, e.Got)
+}
+
+// checksumError 返回损坏帧的校验错误
+func checksumError(frame []byte) *ChecksumError {
+	n := len(frame)
+	return &ChecksumError{Want: codec.XOR(frame[:n-1]), Got: frame[n-1]}
+}
+
+// isHead 判断是否为响应帧头
+func isHead(b byte) bool {
+	return b == headStatus || b == headOpen
+}
+
+// frameLen 返回缓冲区开头 (帧头) 处完整响应帧的长度
+//
+// 响应没有长度字节，按格式逐个尝试帧尾: 开锁应答固定 5 字节；状态应答为以 33 + 校验
+// 结尾的整板状态，或状态字节为 00/11 的 5 字节单锁状态，取最短的校验正确的帧。
+// 数据不足以判断时 complete 为 false；complete 为 true 且 n 为 0 表示没有校验正确的帧。
+func frameLen(buf []byte) (n int, complete bool) {
+	if buf[0] == headOpen {
+		if len(buf) < openFrameLen {
+			return 0, false
+		}
+		if codec.XOR(buf[:openFrameLen]) == 0 {
+			return openFrameLen, true
+		}
+		return 0, true
+	}
+
+	for n = minFrameLen; n <= len(buf) && n <= maxFrameLen; n++ {
+		if codec.XOR(buf[:n]) != 0 {
+			continue
+		}
+		if buf[n-2] == statusTail {
+			return n, true
+		}
+		if n == openFrameLen && (buf[3] == stateOpen || buf[3] == stateClosed) {
+			return n, true
+		}
+	}
+	return 0, len(buf) >= maxFrameLen
+}
+
+// FrameReader 按帧读取锁控板响应
+//
+// 收到一个完整帧后立即返回，不等待读取超时；多个粘连的帧会保留在缓冲区中，
+// 由后续的 ReadFrame 依次返回。帧头之前的无效字节会被丢弃；帧头处的数据无法组成
+// 有效帧时，跳到之后的帧头重新同步。
+type FrameReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+// NewFrameReader 创建帧读取器
+func NewFrameReader(r io.Reader) *FrameReader {
+	return &FrameReader{r: r}
+}
+
+// Reset 丢弃已缓冲的数据
+func (fr *FrameReader) Reset() {
+	fr.buf = fr.buf[:0]
+}
+
+// Buffered 返回已缓冲但尚未组成完整帧的数据
+func (fr *FrameReader) Buffered() []byte {
+	return fr.buf
+}
+
+// ReadFrame 读取一个完整的响应帧
+//
+// 返回帧的原始字节。帧头处的数据确定无法组成校验正确的帧时 (开锁应答按固定长度判断；
+// 状态应答没有长度字节，在缓冲的数据达到最长帧长或底层读取结束 (包括超时) 时判断)，
+// 从之后的帧头重新同步并返回找到的第一个有效帧；之后也没有有效帧时，同时返回剩余的
+// 原始字节和 *ChecksumError。底层读取错误在缓冲区中没有可判断的数据时原样返回。
+func (fr *FrameReader) ReadFrame() ([]byte, error) {
+	for {
+		frame, ok, err := fr.next(false)
+		if ok {
+			return frame, err
+		}
+
+		chunk := make([]byte, 256)
+		n, err := fr.r.Read(chunk)
+		fr.buf = append(fr.buf, chunk[:n]...)
+		if n > 0 {
+			continue
+		}
+		if err == nil {
+			err = io.ErrNoProgress
+		}
+		// 没有更多数据: 不再等待不完整的帧
+		if frame, ok, ferr := fr.next(true); ok {
+			return frame, ferr
+		}
+		return nil, err
+	}
+}
+
+// next 从缓冲区中取出一个帧，数据不足时 ok 为 false
+//
+// final 为 true 表示不会再有更多数据，不完整的帧按损坏处理。
+func (fr *FrameReader) next(final bool) (frame []byte, ok bool, err error) {
+	// 丢弃帧头之前的无效字节
+	start := 0
+	for start < len(fr.buf) && !isHead(fr.buf[start]) {
+		start++
+	}
+	fr.buf = fr.buf[start:]
+
+	if len(fr.buf) < minFrameLen {
+		return nil, false, nil
+	}
+
+	n, complete := frameLen(fr.buf)
+	if n > 0 {
+		return fr.take(n), true, nil
+	}
+	if !complete && !final {
+		return nil, false, nil
+	}
+
+	// 帧头处没有有效帧 (可能是干扰产生的帧头字节或损坏的帧): 从之后的帧头重新同步。
+	// 帧头处的状态应答还不完整时不做这一步，避免把状态数据中的 80/8A 字节误当作帧头。
+	for i := 1; i < len(fr.buf); i++ {
+		if !isHead(fr.buf[i]) {
+			continue
+		}
+		if len(fr.buf)-i < minFrameLen {
+			if final {
+				break
+			}
+			return nil, false, nil
+		}
+		n, complete := frameLen(fr.buf[i:])
+		if n > 0 {
+			fr.buf = fr.buf[i:]
+			return fr.take(n), true, nil
+		}
+		if !complete && !final {
+			return nil, false, nil
+		}
+	}
+
+	// 之后也没有有效帧: 剩余数据作为一个损坏的帧返回
+	frame = fr.take(len(fr.buf))
+	return frame, true, checksumError(frame)
+}
+
+// take 从缓冲区开头取出 n 字节
+func (fr *FrameReader) take(n int) []byte {
+	frame := append([]byte(nil), fr.buf[:n]...)
+	fr.buf = fr.buf[n:]
+	return frame
+}
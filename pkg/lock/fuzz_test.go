@@ -0,0 +1,131 @@
+package lock
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"hardware-test/pkg/codec"
+)
+
+func FuzzGenerateCommand(f *testing.F) {
+	for _, s := range []string{"80010033", "8a0103", "8A010311", "", "80010", "8G01", "80 01"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		cmd, err := generateCommand(s)
+		if _, stdErr := hex.DecodeString(s); (err == nil) != (stdErr == nil) {
+			t.Fatalf("generateCommand(%q) 错误 = %v, 十六进制解码错误 = %v", s, err, stdErr)
+		}
+		if err != nil {
+			return
+		}
+		if len(cmd) != len(s)/2+1 || codec.XOR(cmd) != 0 {
+			t.Fatalf("generateCommand(%q) = %X: 长度或校验错误", s, cmd)
+		}
+	})
+}
+
+func FuzzGenerateOpenCommand(f *testing.F) {
+	f.Add(1, 3)
+	f.Add(255, 255)
+	f.Add(256, 1)
+	f.Add(-1, 0)
+	f.Fuzz(func(t *testing.T, boardAddr, lockAddr int) {
+		cmd, err := generateOpenCommand(boardAddr, lockAddr)
+		valid := boardAddr >= 0 && boardAddr <= 0xFF && lockAddr >= 0 && lockAddr <= 0xFF
+		if (err == nil) != valid {
+			t.Fatalf("generateOpenCommand(%d, %d) = %X, %v", boardAddr, lockAddr, cmd, err)
+		}
+		if err != nil {
+			return
+		}
+		if len(cmd) != 5 || cmd[0] != headOpen || int(cmd[1]) != boardAddr || int(cmd[2]) != lockAddr || codec.XOR(cmd) != 0 {
+			t.Fatalf("generateOpenCommand(%d, %d) = %X", boardAddr, lockAddr, cmd)
+		}
+	})
+}
+
+// 解析成功的帧重新编码后与原始字节一致
+func FuzzParseFrame(f *testing.F) {
+	for _, s := range []string{"8001000033B2", "80010533B7", "8A01031199", "8A01030088", "8001031193", "80012000", "8A0100"} {
+		b, _ := hex.DecodeString(s)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		frame, err := ParseFrame(data)
+		ParseStatus(data)
+		ParseOpen(data)
+		if err != nil {
+			return
+		}
+		if enc := EncodeFrame(frame.Head, frame.BoardAddr, frame.Data); !bytes.Equal(enc, data) {
+			t.Fatalf("EncodeFrame = %X, 期望 %X", enc, data)
+		}
+	})
+}
+
+func FuzzEncodeStatus(f *testing.F) {
+	f.Add(byte(1), []byte{0x05, 0x80})
+	f.Add(byte(8), []byte{})
+	f.Fuzz(func(t *testing.T, boardAddr byte, bitmap []byte) {
+		if len(bitmap) == 0 {
+			return
+		}
+		if len(bitmap) > maxStatusBytes {
+			bitmap = bitmap[:maxStatusBytes]
+		}
+		locks := make([]bool, len(bitmap)*8)
+		for i := range locks {
+			locks[i] = bitmap[i/8]&(1<<(i%8)) != 0
+		}
+
+		status, err := ParseStatus(EncodeStatus(int(boardAddr), locks))
+		if err != nil {
+			t.Fatalf("ParseStatus: %v", err)
+		}
+		if status.BoardAddr != int(boardAddr) || len(status.Locks) != len(locks) {
+			t.Fatalf("状态 = %s", status)
+		}
+		for i, l := range status.Locks {
+			if l.Lock != i+1 || l.Open != locks[i] {
+				t.Fatalf("锁 %d = %s, 期望 %v", i+1, l, locks[i])
+			}
+		}
+	})
+}
+
+// 任意字节流都能被切分，没有错误的帧都能被解析
+func FuzzFrameReader(f *testing.F) {
+	for _, s := range []string{"8001000033B2", "00FF80010533B7" + "8A01031199", "80FF00", "8001053300", "8A8001050E"} {
+		b, _ := hex.DecodeString(s)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, stream []byte) {
+		fr := NewFrameReader(bytes.NewReader(stream))
+		for i := 0; i <= len(stream); i++ {
+			frame, err := fr.ReadFrame()
+			if err == io.EOF {
+				return
+			}
+			var checksumErr *ChecksumError
+			if err != nil && !errors.As(err, &checksumErr) {
+				t.Fatalf("ReadFrame: %v", err)
+			}
+			if frame == nil {
+				continue
+			}
+			if !isHead(frame[0]) || len(frame) < minFrameLen {
+				t.Fatalf("帧 = %X", frame)
+			}
+			if err == nil {
+				if _, err := ParseFrame(frame); err != nil {
+					t.Fatalf("ParseFrame(%X): %v", frame, err)
+				}
+			}
+		}
+		t.Fatalf("%d 字节的数据流未在 %d 次读取内结束", len(stream), len(stream)+1)
+	})
+}
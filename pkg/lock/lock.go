@@ -1,19 +1,23 @@
 package lock
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"net"
+	"io"
+	"os"
 	"time"
 
-	"github.com/tarm/serial"
+	"hardware-test/pkg/codec"
+	"hardware-test/pkg/transport"
 )
 
 // ConnectionType 连接类型
-type ConnectionType string
+type ConnectionType = transport.Type
 
 const (
-	TypeSerial ConnectionType = "serial"
-	TypeSocket ConnectionType = "socket"
+	TypeSerial = transport.TypeSerial
+	TypeSocket = transport.TypeSocket
 )
 
 // LockStatus 锁状态
@@ -21,62 +25,71 @@ type LockStatus struct {
 	BoardAddr int
 	Data      []byte
 	Length    int
+	Board     *BoardStatus // 解析后的各锁状态，响应无效时为 nil
+	ParseErr  error        // 响应解析错误
 }
 
+// DefaultResponseTimeout 等待锁控板响应帧的默认超时
+const DefaultResponseTimeout = 2 * time.Second
+
 // Controller 锁控板控制器
 type Controller struct {
 	connType    ConnectionType
-	path        string
-	baudRate    int
-	port        int
-	socketConn  net.Conn
-	serialConn  *serial.Port
+	dial        transport.Dialer
+	conn        transport.Transport
+	frames      *FrameReader
+	timeout     time.Duration
 	isConnected bool
+	lastResp    []byte
+	out         io.Writer
 }
 
 // NewController 创建锁控板控制器实例
 func NewController(connType ConnectionType, path string, baudRate, port int) *Controller {
+	cfg := transport.Config{
+		Type:     connType,
+		Address:  path,
+		BaudRate: baudRate,
+		Port:     port,
+	}
 	return &Controller{
 		connType: connType,
-		path:     path,
-		baudRate: baudRate,
-		port:     port,
+		dial:     cfg.Dialer(),
+		timeout:  DefaultResponseTimeout,
+		out:      os.Stdout,
 	}
 }
 
-// Connect 连接锁控板
-func (c *Controller) Connect() error {
-	if c.connType == TypeSerial {
-		return c.connectSerial()
-	}
-	return c.connectSocket()
+// NewControllerWithDialer 使用自定义传输创建锁控板控制器实例
+func NewControllerWithDialer(dial transport.Dialer) *Controller {
+	return &Controller{dial: dial, timeout: DefaultResponseTimeout, out: os.Stdout}
 }
 
-// connectSerial 串口连接
-func (c *Controller) connectSerial() error {
-	config := &serial.Config{
-		Name:        c.path,
-		Baud:        c.baudRate,
-		ReadTimeout: 5 * time.Second,
-	}
+// SetOutput 设置测试过程信息的输出 (默认标准输出)
+func (c *Controller) SetOutput(w io.Writer) {
+	c.out = w
+}
 
-	conn, err := serial.OpenPort(config)
-	if err != nil {
-		return fmt.Errorf("锁控板串口连接失败: %w", err)
-	}
-	c.serialConn = conn
-	c.isConnected = true
-	return nil
+// SetResponseTimeout 设置等待响应帧的超时 (没有锁控板的地址需要等待完整超时)
+func (c *Controller) SetResponseTimeout(timeout time.Duration) {
+	c.timeout = timeout
 }
 
-// connectSocket Socket 连接
-func (c *Controller) connectSocket() error {
-	addr := fmt.Sprintf("%s:%d", c.path, c.port)
-	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+// Connect 连接锁控板
+func (c *Controller) Connect() error {
+	conn, err := c.dial()
 	if err != nil {
-		return fmt.Errorf("锁控板 Socket 连接失败: %w", err)
+		switch c.connType {
+		case TypeSerial:
+			return fmt.Errorf("锁控板串口连接失败: %w", err)
+		case TypeSocket:
+			return fmt.Errorf("锁控板 Socket 连接失败: %w", err)
+		default:
+			return fmt.Errorf("锁控板连接失败: %w", err)
+		}
 	}
-	c.socketConn = conn
+	c.conn = conn
+	c.frames = NewFrameReader(conn)
 	c.isConnected = true
 	return nil
 }
@@ -87,18 +100,9 @@ func (c *Controller) Disconnect() error {
 		return nil
 	}
 
-	var err error
-	if c.serialConn != nil {
-		err = c.serialConn.Close()
-		c.serialConn = nil
-	}
-	if c.socketConn != nil {
-		err2 := c.socketConn.Close()
-		if err == nil {
-			err = err2
-		}
-		c.socketConn = nil
-	}
+	err := c.conn.Close()
+	c.conn = nil
+	c.frames = nil
 	c.isConnected = false
 	return err
 }
@@ -108,14 +112,7 @@ func (c *Controller) Write(data []byte) (int, error) {
 	if !c.isConnected {
 		return 0, fmt.Errorf("未连接")
 	}
-
-	if c.serialConn != nil {
-		return c.serialConn.Write(data)
-	}
-	if c.socketConn != nil {
-		return c.socketConn.Write(data)
-	}
-	return 0, fmt.Errorf("未连接")
+	return c.conn.Write(data)
 }
 
 // Read 读取数据
@@ -123,80 +120,46 @@ func (c *Controller) Read(data []byte) (int, error) {
 	if !c.isConnected {
 		return 0, fmt.Errorf("未连接")
 	}
-
-	if c.serialConn != nil {
-		return c.serialConn.Read(data)
-	}
-	if c.socketConn != nil {
-		return c.socketConn.Read(data)
-	}
-	return 0, fmt.Errorf("未连接")
+	return c.conn.Read(data)
 }
 
 // generateCommand 生成锁控板命令
-func generateCommand(hexCmd string) []byte {
+func generateCommand(hexCmd string) ([]byte, error) {
 	// 命令格式: 所有字节异或校验
-	bytes := make([]byte, len(hexCmd)/2)
-	for i := 0; i < len(hexCmd); i += 2 {
-		b := parseHexByte(hexCmd, i)
-		bytes[i/2] = b
-	}
-
-	// 计算校验码
-	checksum := bytes[0]
-	for i := 1; i < len(bytes); i++ {
-		checksum ^= bytes[i]
+	data, err := codec.DecodeHex(hexCmd)
+	if err != nil {
+		return nil, fmt.Errorf("无效的锁控板命令 %q: %w", hexCmd, err)
 	}
 
 	// 添加校验码
-	result := make([]byte, len(bytes)+1)
-	copy(result, bytes)
-	result[len(bytes)] = checksum
-
-	return result
-}
-
-// parseHexByte 从十六进制字符串解析一个字节
-func parseHexByte(s string, i int) byte {
-	b1 := s[i]
-	b2 := s[i+1]
-	var v byte
-	switch {
-	case b1 >= '0' && b1 <= '9':
-		v = (b1 - '0') << 4
-	case b1 >= 'A' && b1 <= 'F':
-		v = (b1 - 'A' + 10) << 4
-	case b1 >= 'a' && b1 <= 'f':
-		v = (b1 - 'a' + 10) << 4
-	}
-
-	switch {
-	case b2 >= '0' && b2 <= '9':
-		v |= b2 - '0'
-	case b2 >= 'A' && b2 <= 'F':
-		v |= b2 - 'A' + 10
-	case b2 >= 'a' && b2 <= 'f':
-		v |= b2 - 'a' + 10
-	}
-	return v
+	return append(data, codec.XOR(data)), nil
 }
 
 // generateQueryCommand 生成查询命令
-func generateQueryCommand() []byte {
+func generateQueryCommand() ([]byte, error) {
 	return generateCommand("80010033")
 }
 
 // generateQueryAllCommand 生成查询所有锁状态命令
-func generateQueryAllCommand(boardAddr int) []byte {
-	boardHex := fmt.Sprintf("%02X", boardAddr)
+func generateQueryAllCommand(boardAddr int) ([]byte, error) {
+	boardHex, err := codec.HexByte("板地址", boardAddr)
+	if err != nil {
+		return nil, err
+	}
 	hexCmd := "80" + boardHex + "01"
 	return generateCommand(hexCmd)
 }
 
 // generateOpenCommand 生成开锁命令
-func generateOpenCommand(boardAddr, lockAddr int) []byte {
-	boardHex := fmt.Sprintf("%02X", boardAddr)
-	lockHex := fmt.Sprintf("%02X", lockAddr)
+func generateOpenCommand(boardAddr, lockAddr int) ([]byte, error) {
+	boardHex, err := codec.HexByte("板地址", boardAddr)
+	if err != nil {
+		return nil, err
+	}
+	lockHex, err := codec.HexByte("锁地址", lockAddr)
+	if err != nil {
+		return nil, err
+	}
 	hexCmd := "8A" + boardHex + lockHex + "11"
 	return generateCommand(hexCmd)
 }
@@ -206,8 +169,11 @@ func (c *Controller) Query() error {
 	if !c.isConnected {
 		return fmt.Errorf("未连接")
 	}
-	cmd := generateQueryCommand()
-	_, err := c.Write(cmd)
+	cmd, err := generateQueryCommand()
+	if err != nil {
+		return err
+	}
+	_, err = c.Write(cmd)
 	return err
 }
 
@@ -220,57 +186,95 @@ func (c *Controller) QueryAll() ([]LockStatus, error) {
 	var allStatus []LockStatus
 
 	for boardAddr := 1; boardAddr <= 8; boardAddr++ {
-		cmd := generateQueryAllCommand(boardAddr)
-
-		if c.serialConn != nil {
-			c.serialConn.Flush()
+		cmd, err := generateQueryAllCommand(boardAddr)
+		if err != nil {
+			return nil, err
 		}
-
-		_, err := c.Write(cmd)
+		data, err := c.request(cmd, headStatus, boardAddr)
 		if err != nil {
-			return nil, fmt.Errorf("查询板地址 %d 失败: %w", boardAddr, err)
+			// 超时表示该地址没有锁控板
+			if transport.IsTimeout(err) {
+				continue
+			}
+			if !isFrameError(err) {
+				return nil, fmt.Errorf("查询板地址 %d 失败: %w", boardAddr, err)
+			}
 		}
 
-		time.Sleep(50 * time.Millisecond)
-
-		if c.socketConn != nil {
-			c.socketConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		status := LockStatus{
+			BoardAddr: boardAddr,
+			Data:      data,
+			Length:    len(data),
+			ParseErr:  err,
+		}
+		if err == nil {
+			status.Board, status.ParseErr = ParseStatus(data)
 		}
+		allStatus = append(allStatus, status)
+	}
 
-		buf := make([]byte, 256)
-		totalRead := 0
+	return allStatus, nil
+}
 
-		for {
-			n, err := c.Read(buf[totalRead:])
-			if err != nil {
-				if err.Error() == "EOF" && totalRead > 0 {
-					break
-				}
-				return nil, fmt.Errorf("读取板地址 %d 响应失败: %w", boardAddr, err)
-			}
+// isFrameError 判断是否为响应帧校验错误
+func isFrameError(err error) bool {
+	var checksumErr *ChecksumError
+	return errors.As(err, &checksumErr)
+}
 
-			if n == 0 {
-				break
-			}
+// request 发送命令并等待指定帧头和板地址的响应帧
+//
+// 其他板地址或其他命令的响应帧 (如之前开锁命令的应答) 会被跳过。应答与命令格式相同，
+// 与命令完全相同的帧视为回显 (如串口环回) 也会被跳过。
+// 帧错误时返回已收到的原始字节和 *ChecksumError。
+func (c *Controller) request(cmd []byte, head byte, boardAddr int) ([]byte, error) {
+	c.conn.Flush()
+	c.frames.Reset()
+
+	if _, err := c.Write(cmd); err != nil {
+		return nil, err
+	}
 
-			totalRead += n
+	c.conn.SetReadDeadline(time.Now().Add(c.timeout))
+	defer c.conn.SetReadDeadline(time.Time{})
 
-			if totalRead >= len(buf) {
-				break
-			}
+	for {
+		frame, err := c.frames.ReadFrame()
+		if err != nil {
+			return frame, err
 		}
+		if bytes.Equal(frame, cmd) {
+			continue
+		}
+		if frame[0] == head && int(frame[1]) == boardAddr {
+			return frame, nil
+		}
+	}
+}
 
-		if totalRead > 0 {
-			status := LockStatus{
-				BoardAddr: boardAddr,
-				Data:      buf[:totalRead],
-				Length:    totalRead,
-			}
-			allStatus = append(allStatus, status)
+// QueryStatus 查询指定锁控板上所有锁的状态
+func (c *Controller) QueryStatus(boardAddr int) (*BoardStatus, error) {
+	if !c.isConnected {
+		return nil, fmt.Errorf("未连接")
+	}
+
+	cmd, err := generateQueryAllCommand(boardAddr)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.request(cmd, headStatus, boardAddr)
+	if err != nil {
+		if transport.IsTimeout(err) {
+			return nil, fmt.Errorf("板地址 %d 无响应", boardAddr)
 		}
+		return nil, fmt.Errorf("查询板地址 %d 失败: %w", boardAddr, err)
 	}
 
-	return allStatus, nil
+	status, err := ParseStatus(data)
+	if err != nil {
+		return nil, fmt.Errorf("板地址 %d 响应无效: %w", boardAddr, err)
+	}
+	return status, nil
 }
 
 // Open 打开指定的锁
@@ -278,11 +282,33 @@ func (c *Controller) Open(boardAddr, lockAddr int) error {
 	if !c.isConnected {
 		return fmt.Errorf("未连接")
 	}
-	cmd := generateOpenCommand(boardAddr, lockAddr)
-	_, err := c.Write(cmd)
+	cmd, err := generateOpenCommand(boardAddr, lockAddr)
+	if err != nil {
+		return err
+	}
+	_, err = c.Write(cmd)
 	return err
 }
 
+// LastResponse 返回最近一次测试收到的原始响应
+func (c *Controller) LastResponse() []byte {
+	return c.lastResp
+}
+
+// Probe 发送查询命令并等待 1 号板的状态帧，返回收到的响应帧
+//
+// 不输出日志，用于扫描串口时判断对端是否为锁控板，超时时间为 SetResponseTimeout 设置的值。
+func (c *Controller) Probe() ([]byte, error) {
+	if !c.isConnected {
+		return nil, fmt.Errorf("未连接")
+	}
+	cmd, err := generateQueryCommand()
+	if err != nil {
+		return nil, err
+	}
+	return c.request(cmd, headStatus, 1)
+}
+
 // TestConnection 测试连接
 func (c *Controller) TestConnection() (bool, error) {
 	if err := c.Connect(); err != nil {
@@ -290,32 +316,18 @@ func (c *Controller) TestConnection() (bool, error) {
 	}
 	defer c.Disconnect()
 
-	// 发送查询命令
-	if err := c.Query(); err != nil {
+	// 发送查询命令并等待 1 号板的状态帧
+	cmd, err := generateQueryCommand()
+	if err != nil {
 		return false, err
 	}
-
-	// 设置读取超时
-	if c.serialConn != nil {
-		c.serialConn.Flush()
+	data, err := c.request(cmd, headStatus, 1)
+	if len(data) > 0 {
+		c.lastResp = data
+		fmt.Fprintf(c.out, "锁控板响应: %X\n", data)
 	}
-
-	// 读取响应
-	buf := make([]byte, 256)
-	if c.serialConn != nil {
-		// 串口超时已在 OpenPort 时设置
-	} else if c.socketConn != nil {
-		c.socketConn.SetReadDeadline(time.Now().Add(3 * time.Second))
-	}
-
-	n, err := c.Read(buf)
 	if err != nil {
 		return false, fmt.Errorf("读取响应失败: %w", err)
 	}
-	if n > 0 {
-		fmt.Printf("锁控板响应: %X\n", buf[:n])
-		return true, nil
-	}
-
-	return false, fmt.Errorf("无响应")
+	return true, nil
 }
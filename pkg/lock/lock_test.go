@@ -0,0 +1,253 @@
+package lock
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"hardware-test/pkg/internal/testutil"
+)
+
+// 命令帧与 testdata/commands.txt 中的黄金样例逐字节比对，样例的来源见文件头部
+func TestGenerateCommand(t *testing.T) {
+	cmds := map[string]func() ([]byte, error){
+		"查询":           generateQueryCommand,
+		"查询 1 号板":      func() ([]byte, error) { return generateQueryAllCommand(1) },
+		"查询 2 号板":      func() ([]byte, error) { return generateQueryAllCommand(2) },
+		"查询 8 号板":      func() ([]byte, error) { return generateQueryAllCommand(8) },
+		"开 1 号板 3 号锁":  func() ([]byte, error) { return generateOpenCommand(1, 3) },
+		"开 2 号板 16 号锁": func() ([]byte, error) { return generateOpenCommand(2, 16) },
+	}
+	seen := make(map[string]bool)
+	for _, golden := range testutil.Golden(t, "testdata/commands.txt") {
+		seen[golden.Name] = true
+		t.Run(golden.Name, func(t *testing.T) {
+			gen, ok := cmds[golden.Name]
+			if !ok {
+				t.Fatalf("没有生成该命令的代码 (来源: %s)", golden.Source)
+			}
+			cmd, err := gen()
+			if err != nil {
+				t.Fatalf("生成命令失败: %v", err)
+			}
+			if !bytes.Equal(cmd, golden.Bytes) {
+				t.Errorf("命令 = % X, 期望 % X (来源: %s)", cmd, golden.Bytes, golden.Source)
+			}
+		})
+	}
+	for name := range cmds {
+		if !seen[name] {
+			t.Errorf("%s: 没有黄金样例", name)
+		}
+	}
+}
+
+// 小写十六进制与大写生成相同的帧
+func TestGenerateCommandLowercase(t *testing.T) {
+	cmd, err := generateCommand("8a0103")
+	if err != nil {
+		t.Fatalf("生成命令失败: %v", err)
+	}
+	if got := strings.ToUpper(hex.EncodeToString(cmd)); got != "8A010388" {
+		t.Errorf("命令 = %s, 期望 8A010388", got)
+	}
+}
+
+// 超出范围的地址和非法的十六进制不能生成错位的帧
+func TestGenerateCommandErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  func() ([]byte, error)
+	}{
+		{"板地址超出范围", func() ([]byte, error) { return generateQueryAllCommand(300) }},
+		{"负数板地址", func() ([]byte, error) { return generateOpenCommand(-1, 3) }},
+		{"锁地址超出范围", func() ([]byte, error) { return generateOpenCommand(1, 256) }},
+		{"奇数长度", func() ([]byte, error) { return generateCommand("80010") }},
+		{"非法字符", func() ([]byte, error) { return generateCommand("8G0100") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cmd, err := tt.cmd(); err == nil {
+				t.Errorf("命令 = %X, 期望返回错误", cmd)
+			}
+		})
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame string
+		open  []int
+		locks int
+	}{
+		{"全部关闭", "8001000033B2", nil, 16},
+		{"1 号和 3 号打开", "80010533B7", []int{1, 3}, 8},
+		{"跨字节", "800201803330", []int{1, 16}, 16},
+		{"单锁打开", "8001031193", []int{3}, 1},
+		{"单锁关闭", "8001030082", nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := ParseStatus(testutil.MustHex(t, tt.frame))
+			if err != nil {
+				t.Fatalf("ParseStatus: %v", err)
+			}
+			if len(status.Locks) != tt.locks {
+				t.Errorf("锁数量 = %d, 期望 %d", len(status.Locks), tt.locks)
+			}
+			if got := status.OpenLocks(); !equalInts(got, tt.open) {
+				t.Errorf("打开的锁 = %v, 期望 %v", got, tt.open)
+			}
+		})
+	}
+}
+
+func TestParseFrameErrors(t *testing.T) {
+	var checksumErr *ChecksumError
+	if _, err := ParseFrame(testutil.MustHex(t, "8001053300")); !errors.As(err, &checksumErr) {
+		t.Errorf("错误 = %v, 期望 *ChecksumError", err)
+	}
+
+	for _, frame := range []string{"", "8001", "80010533", "5501000054"} {
+		if _, err := ParseFrame(testutil.MustHex(t, frame)); err == nil {
+			t.Errorf("ParseFrame(%s) 应返回错误", frame)
+		}
+	}
+
+	// 校验正确但不是已知的状态应答格式
+	for _, frame := range []string{"80010322" + "A0", "80010534B0"} {
+		if _, err := ParseStatus(testutil.MustHex(t, frame)); err == nil {
+			t.Errorf("ParseStatus(%s) 应返回错误", frame)
+		}
+	}
+}
+
+func TestParseOpen(t *testing.T) {
+	tests := []struct {
+		frame  string
+		lock   int
+		opened bool
+	}{
+		{"8A01031199", 3, true},
+		{"8A01030088", 3, false},
+	}
+	for _, tt := range tests {
+		lockAddr, opened, err := ParseOpen(testutil.MustHex(t, tt.frame))
+		if err != nil {
+			t.Fatalf("ParseOpen(%s): %v", tt.frame, err)
+		}
+		if lockAddr != tt.lock || opened != tt.opened {
+			t.Errorf("ParseOpen(%s) = %d, %v, 期望 %d, %v", tt.frame, lockAddr, opened, tt.lock, tt.opened)
+		}
+	}
+
+	if _, _, err := ParseOpen(testutil.MustHex(t, "80010533B7")); err == nil {
+		t.Error("状态帧不应被解析为开锁响应")
+	}
+	if _, _, err := ParseOpen(testutil.MustHex(t, "8A01032288")); err == nil {
+		t.Error("未知的状态字节应返回错误")
+	}
+}
+
+func TestEncodeFrame(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"状态", EncodeStatus(1, []bool{true, false, true, false, false, false, false, false}), "80010533B7"},
+		{"状态 16 把锁", EncodeStatus(2, append(make([]bool, 15), true)), "800200803331"},
+		{"开锁成功", EncodeOpen(1, 3, true), "8A01031199"},
+		{"开锁失败", EncodeOpen(1, 3, false), "8A01030088"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.ToUpper(hex.EncodeToString(tt.got))
+			if got != tt.want {
+				t.Errorf("帧 = %s, 期望 %s", got, tt.want)
+			}
+			if _, err := ParseFrame(tt.got); err != nil {
+				t.Errorf("ParseFrame: %v", err)
+			}
+		})
+	}
+}
+
+func TestFrameReader(t *testing.T) {
+	// 无效字节 + 粘连的整板状态、开锁应答、单锁状态 + 校验错误的帧，逐字节到达
+	stream := testutil.MustHex(t, "0055"+"80010533B7"+"8A01031199"+"8001031193"+"8001053300")
+	fr := NewFrameReader(iotest.OneByteReader(bytes.NewReader(stream)))
+
+	for _, want := range []string{"80010533B7", "8A01031199", "8001031193"} {
+		frame, err := fr.ReadFrame()
+		if err != nil {
+			t.Fatalf("ReadFrame: %v", err)
+		}
+		if got := strings.ToUpper(hex.EncodeToString(frame)); got != want {
+			t.Errorf("帧 = %s, 期望 %s", got, want)
+		}
+	}
+
+	// 状态应答没有长度字节，校验错误在数据结束时才能确定
+	var checksumErr *ChecksumError
+	frame, err := fr.ReadFrame()
+	if !errors.As(err, &checksumErr) {
+		t.Errorf("错误 = %v, 期望 *ChecksumError", err)
+	}
+	if got := strings.ToUpper(hex.EncodeToString(frame)); got != "8001053300" {
+		t.Errorf("损坏的帧 = %s, 期望 8001053300", got)
+	}
+	if _, err := fr.ReadFrame(); err != io.EOF {
+		t.Errorf("错误 = %v, 期望 io.EOF", err)
+	}
+}
+
+func TestFrameReaderResync(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []string
+	}{
+		// 开锁应答按固定长度判断，立即跳到之后的帧头
+		{"多余的 8A", "8A" + "80010533B7", []string{"80010533B7"}},
+		{"损坏的开锁应答", "8A01032288" + "8A01031199", []string{"8A01031199"}},
+		// 状态应答在数据结束时才能确定无效，之后再重新同步
+		{"多余的 80", "80" + "80010533B7", []string{"80010533B7"}},
+		{"多余的 80 后粘连两个帧", "80" + "8001031193" + "8A01030088", []string{"8001031193", "8A01030088"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 数据一次到达: 已缓冲的数据之后有有效帧时不报告前面的损坏数据
+			fr := NewFrameReader(bytes.NewReader(testutil.MustHex(t, tt.stream)))
+			for _, want := range tt.want {
+				frame, err := fr.ReadFrame()
+				if err != nil {
+					t.Fatalf("ReadFrame: %v", err)
+				}
+				if got := strings.ToUpper(hex.EncodeToString(frame)); got != want {
+					t.Errorf("帧 = %s, 期望 %s", got, want)
+				}
+			}
+			if _, err := fr.ReadFrame(); err != io.EOF {
+				t.Errorf("错误 = %v, 期望 io.EOF", err)
+			}
+		})
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+package lock
+
+import (
+	"fmt"
+	"strings"
+
+	"hardware-test/pkg/codec"
+)
+
+// 响应帧格式与命令帧相同，没有长度字节，末字节为之前所有字节的异或:
+//
+//	开锁应答:     8A + 板地址 + 锁地址 + 状态 + 校验     状态 0x11 已打开, 0x00 未打开
+//	单锁状态应答: 80 + 板地址 + 锁地址 + 状态 + 校验
+//	整板状态应答: 80 + 板地址 + 状态位图(N) + 33 + 校验
+//
+// 依据: README「锁控板协议」中的命令帧 (查询 80 01 00 33，开锁 8A + 板地址 + 锁地址 + 11)
+// 和协议说明书 (/packages/lock-control/docs/两路锁控板通讯协议说明书.md) 的字段顺序，
+// 第 3 字节是锁地址 (00 表示整板) 而不是长度。
+//
+// 整板状态的位图字节数随板上锁数变化，位序 (第 j 字节第 i 位、低位在前对应锁号 j*8+i+1，
+// 1 表示打开) 尚未用真机应答核对，因此解析时不按锁数限制位图长度。
+
+const (
+	headStatus byte = 0x80
+	headOpen   byte = 0x8A
+
+	stateOpen   byte = 0x11 // 锁已打开
+	stateClosed byte = 0x00 // 锁未打开
+	statusTail  byte = 0x33 // 整板状态应答中位图之后的固定字节
+
+	// minFrameLen 最短响应帧: 帧头 + 板地址 + 2 字节数据 + 校验
+	minFrameLen = 5
+	// openFrameLen 开锁应答和单锁状态应答的长度
+	openFrameLen = 5
+	// maxStatusBytes 整板状态位图的最大字节数 (256 把锁)
+	maxStatusBytes = 32
+	// maxFrameLen 最长响应帧: 帧头 + 板地址 + 位图 + 33 + 校验
+	maxFrameLen = 2 + maxStatusBytes + 2
+)
+
+// Frame 锁控板响应帧
+type Frame struct {
+	Head      byte
+	BoardAddr int
+	Data      []byte // 板地址和校验之间的数据
+}
+
+// LockState 单个锁的状态
+type LockState struct {
+	Lock int
+	Open bool
+}
+
+// String 返回锁状态描述
+func (s LockState) String() string {
+	if s.Open {
+		return "OPEN"
+	}
+	return "CLOSED"
+}
+
+// BoardStatus 锁控板上所有锁的状态
+type BoardStatus struct {
+	BoardAddr int
+	Locks     []LockState
+}
+
+// Lock 返回指定锁号的状态
+func (s *BoardStatus) Lock(lockAddr int) (LockState, bool) {
+	for _, l := range s.Locks {
+		if l.Lock == lockAddr {
+			return l, true
+		}
+	}
+	return LockState{}, false
+}
+
+// OpenLocks 返回打开的锁号列表
+func (s *BoardStatus) OpenLocks() []int {
+	var open []int
+	for _, l := range s.Locks {
+		if l.Open {
+			open = append(open, l.Lock)
+		}
+	}
+	return open
+}
+
+// String 返回状态描述，如 "板 2: 1=CLOSED 2=OPEN"
+func (s *BoardStatus) String() string {
+	parts := make([]string, 0, len(s.Locks))
+	for _, l := range s.Locks {
+		parts = append(parts, fmt.Sprintf("%d=%s", l.Lock, l))
+	}
+	return fmt.Sprintf("板 %d: %s", s.BoardAddr, strings.Join(parts, " "))
+}
+
+// ParseFrame 解析一个完整的响应帧并校验异或校验码
+func ParseFrame(data []byte) (*Frame, error) {
+	if len(data) < minFrameLen {
+		return nil, fmt.Errorf("响应帧过短: %d 字节", len(data))
+	}
+	if data[0] != headStatus && data[0] != headOpen {
+		return nil, fmt.Errorf("未知帧头: 0x%02X", data[0])
+	}
+	if len(data) > maxFrameLen {
+		return nil, fmt.Errorf("响应帧过长: %d 字节 (最长 %d 字节)", len(data), maxFrameLen)
+	}
+
+	body := data[:len(data)-1]
+	if want, got := codec.XOR(body), data[len(data)-1]; want != got {
+		return nil, &ChecksumError{Want: want, Got: got}
+	}
+
+	return &Frame{
+		Head:      data[0],
+		BoardAddr: int(data[1]),
+		Data:      append([]byte(nil), data[2:len(data)-1]...),
+	}, nil
+}
+
+// ParseStatus 解析状态查询响应 (整板或单锁)，返回每个锁的开关状态
+func ParseStatus(data []byte) (*BoardStatus, error) {
+	frame, err := ParseFrame(data)
+	if err != nil {
+		return nil, err
+	}
+	if frame.Head != headStatus {
+		return nil, fmt.Errorf("不是状态响应帧: 帧头 0x%02X", frame.Head)
+	}
+	return frame.Status()
+}
+
+// Status 将状态帧的数据转换为锁状态列表
+func (f *Frame) Status() (*BoardStatus, error) {
+	status := &BoardStatus{BoardAddr: f.BoardAddr}
+	n := len(f.Data)
+
+	switch {
+	case n >= 2 && f.Data[n-1] == statusTail:
+		// 整板状态: 位图 + 33
+		bitmap := f.Data[:n-1]
+		status.Locks = make([]LockState, 0, len(bitmap)*8)
+		for i, b := range bitmap {
+			for bit := 0; bit < 8; bit++ {
+				status.Locks = append(status.Locks, LockState{
+					Lock: i*8 + bit + 1,
+					Open: b&(1<<bit) != 0,
+				})
+			}
+		}
+	case n == 2:
+		// 单锁状态: 锁地址 + 状态
+		open, err := parseState(f.Data[1])
+		if err != nil {
+			return nil, err
+		}
+		status.Locks = []LockState{{Lock: int(f.Data[0]), Open: open}}
+	default:
+		return nil, fmt.Errorf("无法识别的状态应答数据: % X", f.Data)
+	}
+	return status, nil
+}
+
+// parseState 解析单锁应答中的状态字节
+func parseState(b byte) (bool, error) {
+	switch b {
+	case stateOpen:
+		return true, nil
+	case stateClosed:
+		return false, nil
+	default:
+		return false, fmt.Errorf("未知的锁状态: 0x%02X", b)
+	}
+}
+
+// ParseOpen 解析开锁响应，返回锁地址和是否打开成功
+func ParseOpen(data []byte) (lockAddr int, opened bool, err error) {
+	frame, err := ParseFrame(data)
+	if err != nil {
+		return 0, false, err
+	}
+	if frame.Head != headOpen {
+		return 0, false, fmt.Errorf("不是开锁响应帧: 帧头 0x%02X", frame.Head)
+	}
+	if len(frame.Data) != openFrameLen-3 {
+		return 0, false, fmt.Errorf("开锁响应应为 %d 字节, 实际 %d 字节", openFrameLen, len(data))
+	}
+	opened, err = parseState(frame.Data[1])
+	if err != nil {
+		return 0, false, err
+	}
+	return int(frame.Data[0]), opened, nil
+}
+
+// EncodeFrame 生成响应帧，用于模拟锁控板
+func EncodeFrame(head byte, boardAddr int, data []byte) []byte {
+	frame := make([]byte, 0, len(data)+3)
+	frame = append(frame, head, byte(boardAddr))
+	frame = append(frame, data...)
+	return append(frame, codec.XOR(frame))
+}
+
+// EncodeStatus 生成整板状态响应帧，locks[i] 为锁号 i+1 是否打开
+func EncodeStatus(boardAddr int, locks []bool) []byte {
+	data := make([]byte, (len(locks)+7)/8, (len(locks)+7)/8+1)
+	for i, open := range locks {
+		if open {
+			data[i/8] |= 1 << (i % 8)
+		}
+	}
+	return EncodeFrame(headStatus, boardAddr, append(data, statusTail))
+}
+
+// EncodeOpen 生成开锁响应帧
+func EncodeOpen(boardAddr, lockAddr int, opened bool) []byte {
+	state := stateClosed
+	if opened {
+		state = stateOpen
+	}
+	return EncodeFrame(headOpen, boardAddr, []byte{byte(lockAddr), state})
+}
@@ -0,0 +1,199 @@
+package report
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// 报告格式
+const (
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+// HexBytes 原始字节，JSON 中输出为大写十六进制字符串
+type HexBytes []byte
+
+// MarshalJSON 实现 json.Marshaler
+func (b HexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToUpper(hex.EncodeToString(b)))
+}
+
+// Result 单个模块的测试结果
+type Result struct {
+	Module    string        `json:"module"`
+	Endpoint  string        `json:"endpoint"`
+	Passed    bool          `json:"passed"`
+	Error     string        `json:"error,omitempty"`
+	Response  HexBytes      `json:"response,omitempty"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"-"`
+}
+
+// Report 一次测试运行的完整报告
+type Report struct {
+	Hostname  string        `json:"hostname"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"-"`
+	Results   []Result      `json:"results"`
+}
+
+// New 创建测试报告
+func New() *Report {
+	hostname, _ := os.Hostname()
+	return &Report{
+		Hostname:  hostname,
+		StartedAt: time.Now(),
+	}
+}
+
+// Add 添加一条测试结果
+func (r *Report) Add(result Result) {
+	r.Results = append(r.Results, result)
+}
+
+// Finish 记录总耗时
+func (r *Report) Finish() {
+	r.Duration = time.Since(r.StartedAt)
+}
+
+// Failed 返回失败的结果数量
+func (r *Report) Failed() int {
+	failed := 0
+	for _, res := range r.Results {
+		if !res.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Write 按指定格式输出报告
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		return r.WriteJSON(w)
+	case FormatJUnit:
+		return r.WriteJUnit(w)
+	default:
+		return fmt.Errorf("未知报告格式: %s (应为 json 或 junit)", format)
+	}
+}
+
+// jsonResult JSON 输出用的结果结构 (耗时以毫秒表示)
+type jsonResult struct {
+	Result
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// WriteJSON 输出 JSON 格式报告
+func (r *Report) WriteJSON(w io.Writer) error {
+	results := make([]jsonResult, 0, len(r.Results))
+	for _, res := range r.Results {
+		results = append(results, jsonResult{Result: res, DurationMS: milliseconds(res.Duration)})
+	}
+
+	out := struct {
+		Hostname   string       `json:"hostname"`
+		StartedAt  time.Time    `json:"started_at"`
+		DurationMS float64      `json:"duration_ms"`
+		Total      int          `json:"total"`
+		Passed     int          `json:"passed"`
+		Failed     int          `json:"failed"`
+		Results    []jsonResult `json:"results"`
+	}{
+		Hostname:   r.Hostname,
+		StartedAt:  r.StartedAt,
+		DurationMS: milliseconds(r.Duration),
+		Total:      len(r.Results),
+		Passed:     len(r.Results) - r.Failed(),
+		Failed:     r.Failed(),
+		Results:    results,
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// JUnit XML 结构
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Hostname  string          `xml:"hostname,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit 输出 JUnit XML 格式报告
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:      "hardware-test",
+		Tests:     len(r.Results),
+		Failures:  r.Failed(),
+		Time:      seconds(r.Duration),
+		Timestamp: r.StartedAt.Format("2006-01-02T15:04:05"),
+		Hostname:  r.Hostname,
+	}
+	for _, res := range r.Results {
+		tc := junitTestCase{
+			Name:      res.Endpoint,
+			Classname: "hardware-test." + res.Module,
+			Time:      seconds(res.Duration),
+		}
+		if !res.Passed {
+			tc.Failure = &junitFailure{Message: res.Error, Text: res.Error}
+		}
+		if len(res.Response) > 0 {
+			tc.SystemOut = fmt.Sprintf("response: %X", []byte(res.Response))
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// milliseconds 耗时转换为毫秒
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// seconds 耗时转换为 JUnit 使用的秒数字符串
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
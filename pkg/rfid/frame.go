@@ -0,0 +1,206 @@
+package rfid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// 帧格式: 帧头(1) + 协议控制字(4) + 数据长度(2) + 数据(N) + CRC-16(2)
+const (
+	frameHead byte = 0x5A
+
+	// headerLen 帧头 + 协议控制字 + 数据长度
+	headerLen = 7
+	// crcLen CRC 校验长度
+	crcLen = 2
+	// maxPayloadLen 数据部分的最大长度
+	maxPayloadLen = 1024
+)
+
+// 协议控制字各字段
+const (
+	pcwMIDMask      = 0x000000FF
+	pcwCategoryMask = 0x00000F00
+	pcwUploadFlag   = 0x00001000
+)
+
+// Message 解码后的 RFID 消息
+type Message struct {
+	PCW      uint32 // 协议控制字
+	Category byte   // 消息类别
+	MID      byte   // 消息 ID
+	Upload   bool   // 读写器主动上传 (如标签数据)
+	Payload  []byte // 数据部分
+	Raw      []byte // 完整的原始帧
+}
+
+// String 返回消息的可读描述
+func (m *Message) String() string {
+	return fmt.Sprintf("类别=0x%02X MID=0x%02X 上传=%v 数据=%X", m.Category, m.MID, m.Upload, m.Payload)
+}
+
+// CRCError 帧 CRC 校验错误
+type CRCError struct {
+	Want uint16
+	Got  uint16
+}
+
+func (e *CRCError) Error() string {
+	return fmt.Sprintf("CRC 校验失败: 期望 0x%04X, 实际 0x%04X", e.Want, e.Got)
+}
+
+// LengthError 帧长度错误
+type LengthError struct {
+	Length int // 长度字段的值
+	Actual int // 实际数据字节数，-1 表示长度字段超出范围
+}
+
+func (e *LengthError) Error() string {
+	if e.Actual < 0 {
+		return fmt.Sprintf("数据长度字段超出范围: %d (最大 %d)", e.Length, maxPayloadLen)
+	}
+	return fmt.Sprintf("帧长度不符: 长度字段 %d, 实际 %d 字节", e.Length, e.Actual)
+}
+
+// frameCRC 计算帧的 CRC，校验范围与 buildRFIDCommand 一致 (从帧头到数据末尾)
+func frameCRC(frame []byte) uint16 {
+	return crc16(frame[:len(frame)-crcLen])
+}
+
+// newMessage 从已校验的帧构造消息
+func newMessage(frame []byte) *Message {
+	pcw := binary.BigEndian.Uint32(frame[1:5])
+	return &Message{
+		PCW:      pcw,
+		Category: byte((pcw & pcwCategoryMask) >> 8),
+		MID:      byte(pcw & pcwMIDMask),
+		Upload:   pcw&pcwUploadFlag != 0,
+		Payload:  append([]byte(nil), frame[headerLen:len(frame)-crcLen]...),
+		Raw:      append([]byte(nil), frame...),
+	}
+}
+
+// EncodeFrame 生成一个完整的帧，upload 为 true 时置位上传标志 (读写器主动上传)
+func EncodeFrame(category, mid byte, upload bool, payload []byte) []byte {
+	pcw := uint32(protocolType)<<24 | uint32(protocolVersion)<<16 | uint32(category&0x0F)<<8 | uint32(mid)
+	if upload {
+		pcw |= pcwUploadFlag
+	}
+
+	frame := make([]byte, headerLen, headerLen+len(payload)+crcLen)
+	frame[0] = frameHead
+	binary.BigEndian.PutUint32(frame[1:5], pcw)
+	binary.BigEndian.PutUint16(frame[5:7], uint16(len(payload)))
+	frame = append(frame, payload...)
+	return binary.BigEndian.AppendUint16(frame, crc16(frame))
+}
+
+// DecodeFrame 解码一个完整的帧并校验 CRC
+func DecodeFrame(frame []byte) (*Message, error) {
+	if len(frame) < headerLen+crcLen {
+		return nil, fmt.Errorf("帧过短: %d 字节", len(frame))
+	}
+	if frame[0] != frameHead {
+		return nil, fmt.Errorf("帧头错误: 0x%02X", frame[0])
+	}
+
+	payloadLen := int(binary.BigEndian.Uint16(frame[5:7]))
+	if payloadLen > maxPayloadLen {
+		return nil, &LengthError{Length: payloadLen, Actual: -1}
+	}
+	if len(frame) != headerLen+payloadLen+crcLen {
+		return nil, &LengthError{Length: payloadLen, Actual: len(frame) - headerLen - crcLen}
+	}
+
+	want := frameCRC(frame)
+	got := binary.BigEndian.Uint16(frame[len(frame)-crcLen:])
+	if want != got {
+		return nil, &CRCError{Want: want, Got: got}
+	}
+	return newMessage(frame), nil
+}
+
+// Decoder 从数据流中按帧解码 RFID 消息
+//
+// 帧头之前的无效字节会被丢弃，粘连的多个帧依次返回。
+type Decoder struct {
+	r   io.Reader
+	buf []byte
+}
+
+// NewDecoder 创建帧解码器
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Reset 丢弃已缓冲的数据
+func (d *Decoder) Reset() {
+	d.buf = d.buf[:0]
+}
+
+// Buffered 返回已缓冲但尚未解码的数据
+func (d *Decoder) Buffered() []byte {
+	return d.buf
+}
+
+// ReadMessage 读取并解码一个完整的帧
+//
+// CRC 错误时返回 *CRCError，长度字段超出范围时返回 *LengthError，
+// 两种情况下该帧头都会被跳过。底层读取错误 (包括超时) 原样返回。
+func (d *Decoder) ReadMessage() (*Message, error) {
+	for {
+		msg, ok, err := d.next()
+		if ok {
+			return msg, err
+		}
+
+		chunk := make([]byte, 512)
+		n, err := d.r.Read(chunk)
+		d.buf = append(d.buf, chunk[:n]...)
+		if n > 0 {
+			continue
+		}
+		if err == nil {
+			err = io.ErrNoProgress
+		}
+		return nil, err
+	}
+}
+
+// next 从缓冲区中取出一个帧，数据不足时 ok 为 false
+func (d *Decoder) next() (msg *Message, ok bool, err error) {
+	start := 0
+	for start < len(d.buf) && d.buf[start] != frameHead {
+		start++
+	}
+	d.buf = d.buf[start:]
+
+	if len(d.buf) < headerLen {
+		return nil, false, nil
+	}
+
+	payloadLen := int(binary.BigEndian.Uint16(d.buf[5:7]))
+	if payloadLen > maxPayloadLen {
+		d.buf = d.buf[1:]
+		return nil, true, &LengthError{Length: payloadLen, Actual: -1}
+	}
+
+	frameLen := headerLen + payloadLen + crcLen
+	if len(d.buf) < frameLen {
+		return nil, false, nil
+	}
+
+	frame := d.buf[:frameLen]
+	want := frameCRC(frame)
+	got := binary.BigEndian.Uint16(frame[frameLen-crcLen:])
+	if want != got {
+		// 可能是数据中出现的 0x5A，只跳过该字节以便重新同步
+		d.buf = d.buf[1:]
+		return nil, true, &CRCError{Want: want, Got: got}
+	}
+
+	msg = newMessage(frame)
+	d.buf = d.buf[frameLen:]
+	return msg, true, nil
+}
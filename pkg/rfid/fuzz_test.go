@@ -0,0 +1,161 @@
+package rfid
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+)
+
+func FuzzBuildRFIDCommand(f *testing.F) {
+	for _, s := range []string{"", "0000000F01020006", "0000000f01020006", "000", "0G", "00 01"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, params string) {
+		cmd, err := buildRFIDCommand(cmdReadEPC, params)
+		want, stdErr := hex.DecodeString(params)
+		if (err == nil) != (stdErr == nil) {
+			t.Fatalf("buildRFIDCommand(%q) 错误 = %v, 十六进制解码错误 = %v", params, err, stdErr)
+		}
+		if err != nil {
+			return
+		}
+		if len(want) > maxPayloadLen {
+			return
+		}
+		msg, err := DecodeFrame(cmd)
+		if err != nil {
+			t.Fatalf("DecodeFrame(%X): %v", cmd, err)
+		}
+		if msg.Category != CategoryRFID || msg.MID != 0x10 || msg.Upload || !bytes.Equal(msg.Payload, want) {
+			t.Fatalf("消息 = %s, 期望数据 %X", msg, want)
+		}
+	})
+}
+
+// 解码成功的帧用相同的字段重新编码后与原始字节一致
+func FuzzDecodeFrame(f *testing.F) {
+	for _, s := range []string{"5A000102FF0000604D", "5A000112010001002BDA", "5A00011200000B0004E200123430000101C8FA7C", "5A000102FF0001604D", "5A0001"} {
+		b, _ := hex.DecodeString(s)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, frame []byte) {
+		msg, err := DecodeFrame(frame)
+		if err != nil {
+			return
+		}
+		enc := EncodeFrame(msg.Category, msg.MID, msg.Upload, msg.Payload)
+		if pcw := uint32(enc[1])<<24 | uint32(enc[2])<<16 | uint32(enc[3])<<8 | uint32(enc[4]); pcw != msg.PCW {
+			// 协议控制字中的其他位 (协议类型、版本、保留位) 不由 EncodeFrame 设置
+			return
+		}
+		if !bytes.Equal(enc, frame) {
+			t.Fatalf("EncodeFrame = %X, 期望 %X", enc, frame)
+		}
+	})
+}
+
+// 任意字节流都能被解码，返回的消息都能通过 DecodeFrame 校验
+func FuzzDecoder(f *testing.F) {
+	for _, s := range []string{"5A000102FF0000604D", "00115A000102FF0000604C5A000112010001002BDA", "5AFFFF", "5A5A5A"} {
+		b, _ := hex.DecodeString(s)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, stream []byte) {
+		d := NewDecoder(bytes.NewReader(stream))
+		for i := 0; i <= len(stream); i++ {
+			msg, err := d.ReadMessage()
+			if err == io.EOF {
+				return
+			}
+			var crcErr *CRCError
+			var lengthErr *LengthError
+			if errors.As(err, &crcErr) || errors.As(err, &lengthErr) {
+				continue
+			}
+			if err != nil {
+				t.Fatalf("ReadMessage: %v", err)
+			}
+			if _, err := DecodeFrame(msg.Raw); err != nil {
+				t.Fatalf("DecodeFrame(%X): %v", msg.Raw, err)
+			}
+		}
+		t.Fatalf("%d 字节的数据流未在 %d 次读取内结束", len(stream), len(stream)+1)
+	})
+}
+
+func FuzzParseTagReport(f *testing.F) {
+	for _, s := range []string{
+		"000C" + "E20000112233445566778899" + "3000" + "02" + "0140" + "03" + "0004" + "E2801105",
+		"0004E200123430000101C8",
+		"0002E2003000010300",
+		"0002E20030000107",
+		"00",
+	} {
+		b, _ := hex.DecodeString(s)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		tag, err := ParseTagReport(payload)
+		if err != nil {
+			return
+		}
+		if _, err := hex.DecodeString(tag.EPC); err != nil {
+			t.Fatalf("EPC = %q 不是有效的十六进制", tag.EPC)
+		}
+		if _, err := hex.DecodeString(tag.TID); err != nil {
+			t.Fatalf("TID = %q 不是有效的十六进制", tag.TID)
+		}
+	})
+}
+
+// EncodeTagUpload 生成的帧能被 DecodeFrame 和 ParseTagReport 还原
+func FuzzEncodeTagUpload(f *testing.F) {
+	f.Add("E2001234", "", uint16(0x3000), 1, 200)
+	f.Add("e280689400005012", "E2801105200074E3", uint16(0x3400), 3, 180)
+	f.Add("E20", "", uint16(0), 1, 0)
+	f.Add("E200", "", uint16(0), 256, 0)
+	f.Fuzz(func(t *testing.T, epc, tid string, pc uint16, antenna, rssi int) {
+		frame, err := EncodeTagUpload(TagReport{EPC: epc, TID: tid, PC: pc, Antenna: antenna, RSSI: rssi})
+		if err != nil {
+			return
+		}
+		msg, err := DecodeFrame(frame)
+		if err != nil {
+			if len(frame) > headerLen+maxPayloadLen+crcLen {
+				return
+			}
+			t.Fatalf("DecodeFrame(%X): %v", frame, err)
+		}
+		tag, err := ParseTagReport(msg.Payload)
+		if err != nil {
+			t.Fatalf("ParseTagReport(%X): %v", msg.Payload, err)
+		}
+		if !bytes.EqualFold([]byte(tag.EPC), []byte(epc)) || !bytes.EqualFold([]byte(tag.TID), []byte(tid)) ||
+			tag.PC != pc || tag.Antenna != antenna || tag.RSSI != rssi {
+			t.Fatalf("标签 = %+v", tag)
+		}
+	})
+}
+
+// 解析成功的功率数据按相同格式重新编码后与原始字节一致
+func FuzzParsePower(f *testing.F) {
+	for _, s := range []string{"011E021E031E041E", "", "01", "001E", "211E"} {
+		b, _ := hex.DecodeString(s)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		powers, err := ParsePower(payload)
+		if err != nil {
+			return
+		}
+		var enc []byte
+		for _, p := range powers {
+			enc = append(enc, byte(p.Antenna), byte(p.Power))
+		}
+		if !bytes.Equal(enc, payload) {
+			t.Fatalf("重新编码 = %X, 期望 %X", enc, payload)
+		}
+	})
+}
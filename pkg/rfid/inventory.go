@@ -0,0 +1,246 @@
+package rfid
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"hardware-test/pkg/codec"
+	"hardware-test/pkg/transport"
+)
+
+// 标签上传消息 (类别 RFID, 读写器主动上传)
+const (
+	midTagUpload byte = 0x00 // EPC 数据上传
+	midReadEnd   byte = 0x01 // 读卡结束
+)
+
+// 标签上传数据的可选参数 PID
+const (
+	pidRSSI       byte = 0x01
+	pidReadResult byte = 0x02
+	pidTID        byte = 0x03
+	pidUserData   byte = 0x04
+	pidReserved   byte = 0x05
+	pidSubAntenna byte = 0x06
+	pidUTCTime    byte = 0x07
+	pidFrequency  byte = 0x08
+	pidPhase      byte = 0x09
+)
+
+// inventoryPollInterval 盘点时检查 ctx 是否取消的间隔
+const inventoryPollInterval = 200 * time.Millisecond
+
+// ErrReadEnded 盘点结束前读写器自行上传了读卡结束
+//
+// 读卡结束的结果码不为 0 时，错误中带有结果码。
+var ErrReadEnded = errors.New("读写器结束了读卡")
+
+// TagReport 标签上报
+type TagReport struct {
+	EPC     string    // EPC (十六进制)
+	PC      uint16    // 协议控制位
+	TID     string    // TID (十六进制)，未读取时为空
+	Antenna int       // 天线号
+	RSSI    int       // 信号强度
+	Time    time.Time // 读取时间
+}
+
+// String 返回标签上报的可读描述
+func (t TagReport) String() string {
+	s := fmt.Sprintf("天线 %d EPC=%s RSSI=%d", t.Antenna, t.EPC, t.RSSI)
+	if t.TID != "" {
+		s += " TID=" + t.TID
+	}
+	return s
+}
+
+// ParseTagReport 解析标签上传消息的数据部分
+//
+// 格式: EPC 长度(2) + EPC(N) + PC(2) + 天线号(1) + 可选参数 (PID + 值)
+func ParseTagReport(payload []byte) (*TagReport, error) {
+	p := payload
+	if len(p) < 2 {
+		return nil, fmt.Errorf("标签数据过短: %d 字节", len(payload))
+	}
+	epcLen := int(binary.BigEndian.Uint16(p))
+	p = p[2:]
+	if len(p) < epcLen+3 {
+		return nil, fmt.Errorf("标签数据长度不符: EPC 长度 %d, 剩余 %d 字节", epcLen, len(p))
+	}
+
+	tag := &TagReport{
+		EPC:     fmt.Sprintf("%X", p[:epcLen]),
+		PC:      binary.BigEndian.Uint16(p[epcLen:]),
+		Antenna: int(p[epcLen+2]),
+	}
+	p = p[epcLen+3:]
+
+	for len(p) > 0 {
+		pid := p[0]
+		p = p[1:]
+
+		var n int
+		switch pid {
+		case pidRSSI, pidReadResult, pidSubAntenna, pidPhase:
+			n = 1
+		case pidFrequency:
+			n = 4
+		case pidUTCTime:
+			n = 8
+		case pidTID, pidUserData, pidReserved:
+			if len(p) < 2 {
+				return nil, fmt.Errorf("参数 0x%02X 长度字段不完整", pid)
+			}
+			n = 2 + int(binary.BigEndian.Uint16(p))
+		default:
+			// 未知参数无法确定长度，忽略其后的数据
+			p = nil
+			continue
+		}
+		if len(p) < n {
+			return nil, fmt.Errorf("参数 0x%02X 数据不完整: 需要 %d 字节, 剩余 %d 字节", pid, n, len(p))
+		}
+
+		switch pid {
+		case pidRSSI:
+			tag.RSSI = int(p[0])
+		case pidTID:
+			tag.TID = fmt.Sprintf("%X", p[2:n])
+		case pidUTCTime:
+			sec := binary.BigEndian.Uint32(p)
+			usec := binary.BigEndian.Uint32(p[4:])
+			tag.Time = time.Unix(int64(sec), int64(usec)*1000)
+		}
+		p = p[n:]
+	}
+
+	if tag.Time.IsZero() {
+		tag.Time = time.Now()
+	}
+	return tag, nil
+}
+
+// EncodeTagUpload 生成标签上传帧 (ParseTagReport 的逆过程)，用于模拟读写器
+//
+// TID 不为空时附带 TID 参数；RSSI 总是附带。
+func EncodeTagUpload(tag TagReport) ([]byte, error) {
+	epc, err := codec.DecodeHex(tag.EPC)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 EPC %q: %w", tag.EPC, err)
+	}
+	if tag.Antenna < 0 || tag.Antenna > 0xFF || tag.RSSI < 0 || tag.RSSI > 0xFF {
+		return nil, fmt.Errorf("天线号或 RSSI 超出范围: 天线 %d, RSSI %d", tag.Antenna, tag.RSSI)
+	}
+
+	payload := binary.BigEndian.AppendUint16(nil, uint16(len(epc)))
+	payload = append(payload, epc...)
+	payload = binary.BigEndian.AppendUint16(payload, tag.PC)
+	payload = append(payload, byte(tag.Antenna), pidRSSI, byte(tag.RSSI))
+	if tag.TID != "" {
+		tid, err := codec.DecodeHex(tag.TID)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 TID %q: %w", tag.TID, err)
+		}
+		payload = append(payload, pidTID)
+		payload = binary.BigEndian.AppendUint16(payload, uint16(len(tid)))
+		payload = append(payload, tid...)
+	}
+	return EncodeFrame(CategoryRFID, midTagUpload, true, payload), nil
+}
+
+// EncodeReadEnd 生成读卡结束上传帧
+func EncodeReadEnd() []byte {
+	return EncodeFrame(CategoryRFID, midReadEnd, true, []byte{0x00})
+}
+
+// Inventory 开始连续盘点，每收到一个标签上报调用一次 onTag
+//
+// 一直运行到 ctx 取消 (返回 nil) 或发生错误，结束时发送停止命令。
+// 读取命令的应答之前到达的标签同样交给 onTag；ctx 取消前读写器上传读卡结束时
+// 返回 ErrReadEnded。
+func (r *Reader) Inventory(ctx context.Context, onTag func(TagReport)) error {
+	if r.conn == nil {
+		return fmt.Errorf("未连接")
+	}
+
+	// 先停止之前的操作，丢弃残留数据
+	r.Stop()
+	time.Sleep(100 * time.Millisecond)
+	r.conn.Flush()
+	r.decoder.Reset()
+
+	cmd, err := generateReadEPCCommand(r.antennas)
+	if err != nil {
+		return err
+	}
+	onUpload := func(msg *Message) error {
+		return handleInventoryUpload(ctx, msg, onTag)
+	}
+	resp, err := r.requestUploads(cmd, 3*time.Second, onUpload)
+	if errors.Is(err, ErrReadEnded) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("发送读取命令失败: %w", err)
+	}
+	if len(resp.Payload) > 0 && resp.Payload[0] != 0 {
+		return fmt.Errorf("读取命令失败: 结果码 %d", resp.Payload[0])
+	}
+	defer r.Stop()
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		r.conn.SetReadDeadline(time.Now().Add(inventoryPollInterval))
+		msg, err := r.decoder.ReadMessage()
+		if err != nil {
+			if transport.IsTimeout(err) {
+				continue
+			}
+			var crcErr *CRCError
+			var lengthErr *LengthError
+			if errors.As(err, &crcErr) || errors.As(err, &lengthErr) {
+				// 单个损坏的帧不影响盘点
+				continue
+			}
+			return fmt.Errorf("读取标签数据失败: %w", err)
+		}
+
+		if !msg.Upload {
+			continue
+		}
+		if err := onUpload(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handleInventoryUpload 处理盘点期间的上传消息: 标签交给 onTag，读卡结束时返回错误
+//
+// ctx 已取消时的读卡结束是停止盘点的正常结果，结果码为 0 时不算错误。
+func handleInventoryUpload(ctx context.Context, msg *Message, onTag func(TagReport)) error {
+	if msg.Category != CategoryRFID {
+		return nil
+	}
+	switch msg.MID {
+	case midTagUpload:
+		tag, err := ParseTagReport(msg.Payload)
+		if err != nil {
+			return nil
+		}
+		onTag(*tag)
+	case midReadEnd:
+		if len(msg.Payload) > 0 && msg.Payload[0] != 0 {
+			return fmt.Errorf("%w: 结果码 %d", ErrReadEnded, msg.Payload[0])
+		}
+		if ctx.Err() == nil {
+			return ErrReadEnded
+		}
+	}
+	return nil
+}
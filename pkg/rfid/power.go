@@ -0,0 +1,48 @@
+package rfid
+
+import (
+	"fmt"
+	"time"
+)
+
+// AntennaPower 天线功率
+type AntennaPower struct {
+	Antenna int // 天线号
+	Power   int // 功率 (dBm)
+}
+
+// ParsePower 解析查询功率应答的数据部分
+//
+// 格式: (天线号(1) + 功率(1))...，每个已启用的天线一组，即读 EPC 命令中 "参数 ID + 值" 的
+// 参数写法，以天线号作为参数 ID。
+//
+// 仓库中没有读写器的协议文档 (README「协议文档参考」只有锁控板和串口屏的文档)，这一格式
+// 尚未用真机应答或 rfid-reader.ts 的解析代码核对。模拟读写器按同样的格式应答，模拟测试
+// 不能证明格式正确；解析失败时错误中带有原始数据，便于对照真机应答修正。
+func ParsePower(payload []byte) ([]AntennaPower, error) {
+	if len(payload)%2 != 0 {
+		return nil, fmt.Errorf("功率数据长度错误: %d 字节 (% X)", len(payload), payload)
+	}
+	powers := make([]AntennaPower, 0, len(payload)/2)
+	for i := 0; i < len(payload); i += 2 {
+		if payload[i] < 1 || payload[i] > 32 {
+			return nil, fmt.Errorf("天线号超出范围: %d (% X)", payload[i], payload)
+		}
+		powers = append(powers, AntennaPower{Antenna: int(payload[i]), Power: int(payload[i+1])})
+	}
+	return powers, nil
+}
+
+// Power 查询各天线的功率
+func (r *Reader) Power(timeout time.Duration) ([]AntennaPower, error) {
+	cmd, err := generateQueryPowerCommand()
+	if err != nil {
+		return nil, err
+	}
+	msg, err := r.request(cmd, timeout)
+	if err != nil {
+		return nil, err
+	}
+	r.lastResp = msg.Raw
+	return ParsePower(msg.Payload)
+}
@@ -0,0 +1,163 @@
+package rfid_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"hardware-test/pkg/internal/testutil"
+	"hardware-test/pkg/rfid"
+	"hardware-test/pkg/simulator"
+	"hardware-test/pkg/transport"
+)
+
+func TestReaderProbe(t *testing.T) {
+	r := testutil.Connect(t, rfid.NewReaderWithDialer(testutil.Dial(t, simulator.NewRFIDReader(4)), []int{1}))
+
+	frame, err := r.Probe(time.Second)
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	msg, err := rfid.DecodeFrame(frame)
+	if err != nil {
+		t.Fatalf("DecodeFrame: %v", err)
+	}
+	if msg.Upload || msg.Category != rfid.CategoryRFID || msg.MID != 0x02 {
+		t.Errorf("应答 = %s", msg)
+	}
+}
+
+func TestReaderPower(t *testing.T) {
+	sim := simulator.NewRFIDReader(2)
+	sim.SetPower(2, 25)
+	r := testutil.Connect(t, rfid.NewReaderWithDialer(testutil.Dial(t, sim), []int{1, 2}))
+
+	powers, err := r.Power(time.Second)
+	if err != nil {
+		t.Fatalf("Power: %v", err)
+	}
+	want := []rfid.AntennaPower{{Antenna: 1, Power: 30}, {Antenna: 2, Power: 25}}
+	if len(powers) != len(want) || powers[0] != want[0] || powers[1] != want[1] {
+		t.Errorf("功率 = %v, 期望 %v", powers, want)
+	}
+}
+
+func TestReaderInventory(t *testing.T) {
+	sim := simulator.NewRFIDReader(4)
+	sim.SetTagInterval(20 * time.Millisecond)
+	sim.SetTags([]simulator.Tag{
+		{EPC: "E20000000000000000000001", Antenna: 1, RSSI: 200},
+		{EPC: "E20000000000000000000002", TID: "E2801105200074E3", Antenna: 2, RSSI: 180},
+		{EPC: "E20000000000000000000003", Antenna: 3, RSSI: 150},
+	})
+	r := testutil.Connect(t, rfid.NewReaderWithDialer(testutil.Dial(t, sim), []int{1, 2}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	seen := make(map[string]rfid.TagReport)
+	err := r.Inventory(ctx, func(tag rfid.TagReport) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[tag.EPC] = tag
+		if len(seen) == 2 {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatalf("Inventory: %v", err)
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("读到 %d 个标签: %v", len(seen), seen)
+	}
+	if tag := seen["E20000000000000000000002"]; tag.Antenna != 2 || tag.TID != "E2801105200074E3" || tag.RSSI != 180 {
+		t.Errorf("标签 = %s", tag)
+	}
+	if _, ok := seen["E20000000000000000000003"]; ok {
+		t.Error("读到了未选择天线上的标签")
+	}
+}
+
+// earlyEndReader 读写器: 读 EPC 时先上传一个标签再应答，随后以结果码 code 上传读卡结束
+type earlyEndReader struct {
+	code byte
+}
+
+func (d earlyEndReader) Serve(rw io.ReadWriter) error {
+	dec := rfid.NewDecoder(rw)
+	for {
+		msg, err := dec.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var frames [][]byte
+		readEPC := msg.Category == rfid.CategoryRFID && msg.MID == 0x10
+		if readEPC {
+			tag, err := rfid.EncodeTagUpload(rfid.TagReport{EPC: "E20000000000000000000001", Antenna: 1, RSSI: 200})
+			if err != nil {
+				return err
+			}
+			frames = append(frames, tag)
+		}
+		frames = append(frames, rfid.EncodeFrame(msg.Category, msg.MID, false, []byte{0x00}))
+		if readEPC {
+			// MID 0x01: 读卡结束
+			frames = append(frames, rfid.EncodeFrame(rfid.CategoryRFID, 0x01, true, []byte{d.code}))
+		}
+		for _, frame := range frames {
+			if _, err := rw.Write(frame); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func TestReaderInventoryReadEnd(t *testing.T) {
+	for _, code := range []byte{0, 2} {
+		r := testutil.Connect(t, rfid.NewReaderWithDialer(testutil.Dial(t, earlyEndReader{code: code}), []int{1}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		var tags []string
+		err := r.Inventory(ctx, func(tag rfid.TagReport) { tags = append(tags, tag.EPC) })
+		cancel()
+
+		// 读取命令应答之前上传的标签不丢失，读写器自行结束读卡不被当作正常结束
+		if len(tags) != 1 {
+			t.Errorf("结果码 %d: 读到标签 %v, 期望应答前上传的 1 个", code, tags)
+		}
+		if !errors.Is(err, rfid.ErrReadEnded) {
+			t.Errorf("结果码 %d: 错误 = %v, 期望 ErrReadEnded", code, err)
+		}
+		if code != 0 && (err == nil || !strings.Contains(err.Error(), "结果码 2")) {
+			t.Errorf("错误 = %v, 期望带有结果码", err)
+		}
+	}
+}
+
+func TestReaderFaults(t *testing.T) {
+	t.Run("无应答", func(t *testing.T) {
+		sim := simulator.NewRFIDReader(1)
+		sim.SetFaults(simulator.Faults{DropRate: 1})
+		r := testutil.Connect(t, rfid.NewReaderWithDialer(testutil.Dial(t, sim), []int{1}))
+
+		if _, err := r.Probe(200 * time.Millisecond); !transport.IsTimeout(err) {
+			t.Errorf("错误 = %v, 期望超时", err)
+		}
+	})
+
+	t.Run("数据损坏", func(t *testing.T) {
+		sim := simulator.NewRFIDReader(1)
+		sim.SetFaults(simulator.Faults{CorruptRate: 1})
+		r := testutil.Connect(t, rfid.NewReaderWithDialer(testutil.Dial(t, sim), []int{1}))
+
+		if _, err := r.Probe(200 * time.Millisecond); err == nil {
+			t.Error("应答损坏时 Probe 不应成功")
+		}
+	})
+}
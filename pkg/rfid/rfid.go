@@ -1,58 +1,118 @@
 package rfid
 
 import (
+	"bytes"
 	"fmt"
-	"net"
+	"io"
+	"os"
 	"time"
+
+	"hardware-test/pkg/codec"
+	"hardware-test/pkg/transport"
 )
 
 // Reader RFID 读写器
 type Reader struct {
-	host     string
-	port     int
-	conn     net.Conn
+	dial     transport.Dialer
+	conn     transport.Transport
+	decoder  *Decoder
 	antennas []int
+	lastResp []byte
+	out      io.Writer
 }
 
-// NewReader 创建 RFID 读写器实例
+// NewReader 创建 RFID 读写器实例 (TCP Socket 连接)
 func NewReader(host string, port int, antennas []int) *Reader {
+	cfg := transport.Config{Type: transport.TypeSocket, Address: host, Port: port}
+	return NewReaderWithDialer(cfg.Dialer(), antennas)
+}
+
+// NewSerialReader 创建串口连接的 RFID 读写器实例
+func NewSerialReader(path string, baudRate int, antennas []int) *Reader {
+	cfg := transport.Config{Type: transport.TypeSerial, Address: path, BaudRate: baudRate}
+	return NewReaderWithDialer(cfg.Dialer(), antennas)
+}
+
+// NewReaderWithDialer 使用自定义传输创建 RFID 读写器实例
+func NewReaderWithDialer(dial transport.Dialer, antennas []int) *Reader {
 	return &Reader{
-		host:     host,
-		port:     port,
+		dial:     dial,
 		antennas: antennas,
+		out:      os.Stdout,
 	}
 }
 
+// SetOutput 设置测试过程信息的输出 (默认标准输出)
+func (r *Reader) SetOutput(w io.Writer) {
+	r.out = w
+}
+
 // Connect 连接 RFID 读写器
 func (r *Reader) Connect() error {
-	addr := fmt.Sprintf("%s:%d", r.host, r.port)
-	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	conn, err := r.dial()
 	if err != nil {
 		return fmt.Errorf("RFID 连接失败: %w", err)
 	}
 	r.conn = conn
+	r.decoder = NewDecoder(conn)
 	return nil
 }
 
-// Disconnect 断开连接
+// Disconnect 发送停止命令后断开连接
 func (r *Reader) Disconnect() error {
 	if r.conn != nil {
-		// 发送停止命令
 		r.Stop()
-		return r.conn.Close()
 	}
-	return nil
+	return r.Close()
 }
 
+// Close 断开连接，不发送停止命令
+//
+// 用于探测尚未确认是 RFID 读写器的端点，避免向其他设备发送停止命令。
+func (r *Reader) Close() error {
+	if r.conn == nil {
+		return nil
+	}
+	err := r.conn.Close()
+	r.conn = nil
+	r.decoder = nil
+	return err
+}
+
+// 协议控制字高 16 位: 协议类型号(1) + 协议版本号(1)
+const (
+	protocolType    = 0x00
+	protocolVersion = 0x01
+)
+
+// 消息类别
+const (
+	CategoryError  byte = 0x00 // 错误与警告
+	CategoryConfig byte = 0x01 // 读写器配置与管理
+	CategoryRFID   byte = 0x02 // RFID 配置与操作
+)
+
+// 命令类型: 高 8 位为消息类别，低 8 位为消息 ID (MID)
+const (
+	cmdQueryInfo  uint16 = uint16(CategoryConfig)<<8 | 0x00 // 查询读写器信息
+	cmdQueryPower uint16 = uint16(CategoryRFID)<<8 | 0x02   // 查询读写器功率
+	cmdReadEPC    uint16 = uint16(CategoryRFID)<<8 | 0x10   // 读 EPC 标签
+	cmdStop       uint16 = uint16(CategoryRFID)<<8 | 0xFF   // 停止操作
+)
+
 // buildRFIDCommand 构建 RFID 命令
-func buildRFIDCommand(cmdType uint16, dataParams string) []byte {
+func buildRFIDCommand(cmdType uint16, dataParams string) ([]byte, error) {
 	// 协议: 帧头(1) + 协议控制字(4) + 长度(2) + 数据(N) + 校验(2)
 	// 帧头: 0x5A
-	// 协议控制字: 设备类型(2字节) + 命令类型(1字节) + 命令方向(1字节)
+	// 协议控制字: 协议类型号(1字节) + 协议版本号(1字节) + 消息类别(1字节) + MID(1字节)
+	// 例: 停止命令 5A 000102FF 0000 604D
+	if len(dataParams)%2 != 0 {
+		return nil, fmt.Errorf("无效的 RFID 命令参数 %q: %w", dataParams, codec.ErrOddLength)
+	}
 
-	// 简化版本：直接使用已知格式的命令
-	// 5A + 00000100 + 1000 + length + data + CRC
-	command := "5A00000100"
+	pcw := uint32(protocolType)<<24 | uint32(protocolVersion)<<16 |
+		uint32(cmdType)&(pcwCategoryMask|pcwMIDMask)
+	command := fmt.Sprintf("%08X", pcw)
 
 	// 长度字段 (数据长度，2字节)
 	dataLen := len(dataParams) / 2
@@ -62,85 +122,64 @@ func buildRFIDCommand(cmdType uint16, dataParams string) []byte {
 	// 数据参数
 	command += dataParams
 
-	// CRC 校验 (使用简单的累加异或)
-	crc := calculateCRC(command)
+	// CRC 校验 (从帧头到数据末尾)
+	command = "5A" + command
+	crc, err := calculateCRC(command)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 RFID 命令参数 %q: %w", dataParams, err)
+	}
 	command += crc
 
 	// 转换为字节
-	return hexToBytes(command)
+	return codec.DecodeHex(command)
 }
 
 // calculateCRC 计算 CRC 校验码
-func calculateCRC(hexStr string) string {
-	var crc uint16 = 0xFFFF
-	for i := 0; i < len(hexStr); i += 2 {
-		b := parseHexByte(hexStr, i)
-		crc ^= uint16(b) << 8
-		for j := 0; j < 8; j++ {
-			if crc&0x8000 != 0 {
-				crc = (crc << 1) ^ 0x1021
-			} else {
-				crc = crc << 1
-			}
-		}
-	}
-	return fmt.Sprintf("%04X", crc)
-}
-
-// parseHexByte 从十六进制字符串解析一个字节
-func parseHexByte(s string, i int) byte {
-	b1 := s[i]
-	b2 := s[i+1]
-	var v byte
-	if b1 >= '0' && b1 <= '9' {
-		v = (b1 - '0') << 4
-	} else {
-		v = (b1-'A'+10) << 4
-	}
-	if b2 >= '0' && b2 <= '9' {
-		v |= b2 - '0'
-	} else {
-		v |= b2 - 'A' + 10
+func calculateCRC(hexStr string) (string, error) {
+	data, err := codec.DecodeHex(hexStr)
+	if err != nil {
+		return "", err
 	}
-	return v
+	return fmt.Sprintf("%04X", crc16(data)), nil
 }
 
-// hexToBytes 十六进制字符串转字节
-func hexToBytes(hexStr string) []byte {
-	bytes := make([]byte, len(hexStr)/2)
-	for i := 0; i < len(hexStr); i += 2 {
-		bytes[i/2] = parseHexByte(hexStr, i)
-	}
-	return bytes
+// crc16 计算 CRC-16/CCITT (多项式 0x1021, 初始值 0xFFFF)
+func crc16(data []byte) uint16 {
+	return codec.CRC16(data)
 }
 
 // generateStopCommand 生成停止命令
-func generateStopCommand() []byte {
+func generateStopCommand() ([]byte, error) {
 	// MID = 0xFF, 停止操作命令
-	return buildRFIDCommand(0xFF, "")
+	return buildRFIDCommand(cmdStop, "")
 }
 
 // generateReadEPCCommand 生成读取 EPC 命令
-func generateReadEPCCommand(antennas []int) []byte {
+func generateReadEPCCommand(antennas []int) ([]byte, error) {
+	if len(antennas) == 0 {
+		return nil, fmt.Errorf("未指定天线")
+	}
+
 	// 构建天线端口位掩码
 	var antennaMask uint32
 	for _, ant := range antennas {
-		if ant >= 1 && ant <= 32 {
-			antennaMask |= 1 << (ant - 1)
+		if ant < 1 || ant > 32 {
+			return nil, fmt.Errorf("天线号超出范围: %d (应为 1-32)", ant)
 		}
+		antennaMask |= 1 << (ant - 1)
 	}
 
 	dataParams := fmt.Sprintf("%08X", antennaMask) // 天线端口
-	dataParams += "01"                              // 连续读取
-	dataParams += "02"                              // 读取参数 (TID)
-	dataParams += "0006"                            // TID 读取参数
+	dataParams += "01"                             // 连续读取
+	dataParams += "02"                             // 读取参数 (TID)
+	dataParams += "0006"                           // TID 读取参数
 
-	return buildRFIDCommand(0x10, dataParams)
+	return buildRFIDCommand(cmdReadEPC, dataParams)
 }
 
 // generateQueryPowerCommand 生成查询功率命令
-func generateQueryPowerCommand() []byte {
-	return buildRFIDCommand(0x02, "")
+func generateQueryPowerCommand() ([]byte, error) {
+	return buildRFIDCommand(cmdQueryPower, "")
 }
 
 // Stop 停止读取
@@ -148,8 +187,11 @@ func (r *Reader) Stop() error {
 	if r.conn == nil {
 		return fmt.Errorf("未连接")
 	}
-	cmd := generateStopCommand()
-	_, err := r.conn.Write(cmd)
+	cmd, err := generateStopCommand()
+	if err != nil {
+		return err
+	}
+	_, err = r.conn.Write(cmd)
 	return err
 }
 
@@ -164,8 +206,11 @@ func (r *Reader) StartReading() error {
 	time.Sleep(100 * time.Millisecond)
 
 	// 发送读取命令
-	cmd := generateReadEPCCommand(r.antennas)
-	_, err := r.conn.Write(cmd)
+	cmd, err := generateReadEPCCommand(r.antennas)
+	if err != nil {
+		return err
+	}
+	_, err = r.conn.Write(cmd)
 	return err
 }
 
@@ -174,11 +219,19 @@ func (r *Reader) QueryPower() error {
 	if r.conn == nil {
 		return fmt.Errorf("未连接")
 	}
-	cmd := generateQueryPowerCommand()
-	_, err := r.conn.Write(cmd)
+	cmd, err := generateQueryPowerCommand()
+	if err != nil {
+		return err
+	}
+	_, err = r.conn.Write(cmd)
 	return err
 }
 
+// LastResponse 返回最近一次测试收到的原始响应
+func (r *Reader) LastResponse() []byte {
+	return r.lastResp
+}
+
 // TestConnection 测试连接
 func (r *Reader) TestConnection() (bool, error) {
 	// 连接设备
@@ -187,25 +240,86 @@ func (r *Reader) TestConnection() (bool, error) {
 	}
 	defer r.Disconnect()
 
-	// 发送查询功率命令作为测试
-	if err := r.QueryPower(); err != nil {
+	// 发送查询功率命令作为测试，响应必须是有效帧且 MID 与命令一致
+	cmd, err := generateQueryPowerCommand()
+	if err != nil {
 		return false, err
 	}
+	msg, err := r.request(cmd, 3*time.Second)
+	if err != nil {
+		if buffered := r.decoder.Buffered(); len(buffered) > 0 {
+			r.lastResp = append([]byte(nil), buffered...)
+			fmt.Fprintf(r.out, "RFID 无效响应: %X\n", buffered)
+		}
+		if transport.IsTimeout(err) {
+			return false, fmt.Errorf("未收到有效的 RFID 响应帧: %w", err)
+		}
+		return false, fmt.Errorf("读取响应失败: %w", err)
+	}
 
-	// 设置读取超时
-	r.conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	r.lastResp = msg.Raw
+	fmt.Fprintf(r.out, "RFID 响应: %X (%s)\n", msg.Raw, msg)
+	return true, nil
+}
 
-	// 尝试读取响应
-	buf := make([]byte, 256)
-	n, err := r.conn.Read(buf)
+// Probe 发送查询功率命令，收到有效的应答帧时返回该帧
+//
+// 不输出日志，用于扫描时判断对端是否为 RFID 读写器。
+func (r *Reader) Probe(timeout time.Duration) ([]byte, error) {
+	cmd, err := generateQueryPowerCommand()
 	if err != nil {
-		return false, fmt.Errorf("读取响应失败: %w", err)
+		return nil, err
+	}
+	msg, err := r.request(cmd, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Raw, nil
+}
+
+// request 发送命令并等待与命令类别、MID 相同的应答消息
+//
+// 读写器主动上传的消息 (如标签数据) 和其他命令的应答会被跳过。
+func (r *Reader) request(cmd []byte, timeout time.Duration) (*Message, error) {
+	return r.requestUploads(cmd, timeout, nil)
+}
+
+// requestUploads 与 request 相同，但应答之前收到的主动上传消息交给 onUpload 处理
+//
+// onUpload 返回错误时不再等待应答，直接返回该错误。
+func (r *Reader) requestUploads(cmd []byte, timeout time.Duration, onUpload func(*Message) error) (*Message, error) {
+	if r.conn == nil {
+		return nil, fmt.Errorf("未连接")
+	}
+	req, err := DecodeFrame(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("无效的命令帧: %w", err)
 	}
 
-	if n > 0 {
-		fmt.Printf("RFID 响应: %X\n", buf[:n])
-		return true, nil
+	if _, err := r.conn.Write(cmd); err != nil {
+		return nil, err
 	}
 
-	return false, fmt.Errorf("无响应")
+	r.conn.SetReadDeadline(time.Now().Add(timeout))
+	defer r.conn.SetReadDeadline(time.Time{})
+
+	for {
+		msg, err := r.decoder.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		// 与命令完全相同的帧是回显 (如串口环回或回显服务)，应答总是带有结果数据
+		if bytes.Equal(msg.Raw, cmd) {
+			continue
+		}
+		if msg.Upload && onUpload != nil {
+			if err := onUpload(msg); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !msg.Upload && msg.Category == req.Category && msg.MID == req.MID {
+			return msg, nil
+		}
+	}
 }
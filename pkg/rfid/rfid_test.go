@@ -0,0 +1,245 @@
+package rfid
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"hardware-test/pkg/internal/testutil"
+)
+
+// mustCommand 返回在生成命令失败时终止测试的辅助函数
+func mustCommand(t *testing.T) func([]byte, error) []byte {
+	return func(cmd []byte, err error) []byte {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("生成命令失败: %v", err)
+		}
+		return cmd
+	}
+}
+
+func toHex(b []byte) string {
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+// 命令帧与 testdata/commands.txt 中的黄金样例逐字节比对，样例的来源见文件头部
+func TestBuildRFIDCommand(t *testing.T) {
+	cmds := map[string]func() ([]byte, error){
+		"停止":           generateStopCommand,
+		"查询读写器信息":      func() ([]byte, error) { return buildRFIDCommand(cmdQueryInfo, "") },
+		"查询功率":         generateQueryPowerCommand,
+		"读 EPC 天线 1-4": func() ([]byte, error) { return generateReadEPCCommand([]int{1, 2, 3, 4}) },
+	}
+	seen := make(map[string]bool)
+	for _, golden := range testutil.Golden(t, "testdata/commands.txt") {
+		seen[golden.Name] = true
+		t.Run(golden.Name, func(t *testing.T) {
+			gen, ok := cmds[golden.Name]
+			if !ok {
+				t.Fatalf("没有生成该命令的代码 (来源: %s)", golden.Source)
+			}
+			cmd, err := gen()
+			if err != nil {
+				t.Fatalf("生成命令失败: %v", err)
+			}
+			if !bytes.Equal(cmd, golden.Bytes) {
+				t.Errorf("帧 = % X, 期望 % X (来源: %s)", cmd, golden.Bytes, golden.Source)
+			}
+		})
+	}
+	for name := range cmds {
+		if !seen[name] {
+			t.Errorf("%s: 没有黄金样例", name)
+		}
+	}
+}
+
+func TestBuildRFIDCommandMID(t *testing.T) {
+	must := mustCommand(t)
+	tests := []struct {
+		name     string
+		frame    []byte
+		category byte
+		mid      byte
+	}{
+		{"停止", must(generateStopCommand()), CategoryRFID, 0xFF},
+		{"查询功率", must(generateQueryPowerCommand()), CategoryRFID, 0x02},
+		{"读 EPC", must(generateReadEPCCommand([]int{1})), CategoryRFID, 0x10},
+		{"查询读写器信息", must(buildRFIDCommand(cmdQueryInfo, "")), CategoryConfig, 0x00},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := DecodeFrame(tt.frame)
+			if err != nil {
+				t.Fatalf("DecodeFrame: %v", err)
+			}
+			if msg.Category != tt.category || msg.MID != tt.mid {
+				t.Errorf("类别/MID = 0x%02X/0x%02X, 期望 0x%02X/0x%02X", msg.Category, msg.MID, tt.category, tt.mid)
+			}
+			if msg.Upload {
+				t.Errorf("命令帧不应带上传标志")
+			}
+			if enc := EncodeFrame(msg.Category, msg.MID, false, msg.Payload); !bytes.Equal(enc, tt.frame) {
+				t.Errorf("EncodeFrame = %X, 期望 %X", enc, tt.frame)
+			}
+		})
+	}
+}
+
+func TestCalculateCRC(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"5A000102FF0000", "604D"},
+		{"5A000101000000", "34F2"},
+		{"5A000102ff0000", "604D"},
+		{"", "FFFF"},
+	}
+	for _, tt := range tests {
+		if got, err := calculateCRC(tt.in); err != nil || got != tt.want {
+			t.Errorf("calculateCRC(%q) = %s, %v, 期望 %s", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"000102FF000", "0001 02FF"} {
+		if _, err := calculateCRC(in); err == nil {
+			t.Errorf("calculateCRC(%q) 应返回错误", in)
+		}
+	}
+}
+
+// 小写十六进制与大写结果一致，奇数长度和非法字符返回错误而不是生成错误的帧
+func TestBuildRFIDCommandErrors(t *testing.T) {
+	lower, err := buildRFIDCommand(cmdReadEPC, "0000000f01020006")
+	if err != nil {
+		t.Fatalf("buildRFIDCommand: %v", err)
+	}
+	upper, _ := buildRFIDCommand(cmdReadEPC, "0000000F01020006")
+	if !bytes.Equal(lower, upper) {
+		t.Errorf("小写参数 = %X, 期望 %X", lower, upper)
+	}
+
+	for _, params := range []string{"000", "0G", "00 01"} {
+		if cmd, err := buildRFIDCommand(cmdReadEPC, params); err == nil {
+			t.Errorf("buildRFIDCommand(%q) = %X, 期望返回错误", params, cmd)
+		}
+	}
+	for _, antennas := range [][]int{nil, {0}, {33}, {1, -1}} {
+		if cmd, err := generateReadEPCCommand(antennas); err == nil {
+			t.Errorf("generateReadEPCCommand(%v) = %X, 期望返回错误", antennas, cmd)
+		}
+	}
+}
+
+func TestParseTagReport(t *testing.T) {
+	// EPC 长度 12 + EPC + PC + 天线 2 + RSSI(0x01) + TID(0x03)
+	payload, _ := hex.DecodeString("000C" + "E20000112233445566778899" + "3000" + "02" + "0140" + "03" + "0004" + "E2801105")
+	tag, err := ParseTagReport(payload)
+	if err != nil {
+		t.Fatalf("ParseTagReport: %v", err)
+	}
+	if tag.EPC != "E20000112233445566778899" || tag.PC != 0x3000 || tag.Antenna != 2 || tag.RSSI != 0x40 || tag.TID != "E2801105" {
+		t.Errorf("解析结果错误: %+v", tag)
+	}
+
+	if _, err := ParseTagReport(payload[:10]); err == nil {
+		t.Errorf("截断的数据应返回错误")
+	}
+}
+
+func TestDecodeFrameErrors(t *testing.T) {
+	var crcErr *CRCError
+	if _, err := DecodeFrame(testutil.MustHex(t, "5A000102FF0000604C")); !errors.As(err, &crcErr) {
+		t.Errorf("错误 = %v, 期望 *CRCError", err)
+	}
+
+	var lengthErr *LengthError
+	if _, err := DecodeFrame(testutil.MustHex(t, "5A000102FF0001604D")); !errors.As(err, &lengthErr) {
+		t.Errorf("错误 = %v, 期望 *LengthError", err)
+	}
+	if _, err := DecodeFrame(testutil.MustHex(t, "5A000102FFFFFF604D")); !errors.As(err, &lengthErr) || lengthErr.Actual != -1 {
+		t.Errorf("错误 = %v, 期望超出范围的 *LengthError", err)
+	}
+
+	for _, frame := range []string{"5A0001", "A5000102FF0000885A"} {
+		if _, err := DecodeFrame(testutil.MustHex(t, frame)); err == nil {
+			t.Errorf("DecodeFrame(%s) 应返回错误", frame)
+		}
+	}
+}
+
+func TestTagUpload(t *testing.T) {
+	frame, err := EncodeTagUpload(TagReport{EPC: "E2001234", PC: 0x3000, Antenna: 1, RSSI: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := toHex(frame), "5A00011200000B0004E200123430000101C8FA7C"; got != want {
+		t.Errorf("帧 = %s, 期望 %s", got, want)
+	}
+	if got, want := toHex(EncodeReadEnd()), "5A000112010001002BDA"; got != want {
+		t.Errorf("读卡结束帧 = %s, 期望 %s", got, want)
+	}
+
+	want := TagReport{EPC: "E280689400005012", PC: 0x3400, TID: "E2801105200074E3", Antenna: 3, RSSI: 180}
+	frame, err = EncodeTagUpload(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := DecodeFrame(frame)
+	if err != nil {
+		t.Fatalf("DecodeFrame: %v", err)
+	}
+	if !msg.Upload || msg.Category != CategoryRFID || msg.MID != midTagUpload {
+		t.Errorf("消息 = %s", msg)
+	}
+	got, err := ParseTagReport(msg.Payload)
+	if err != nil {
+		t.Fatalf("ParseTagReport: %v", err)
+	}
+	if got.EPC != want.EPC || got.PC != want.PC || got.TID != want.TID ||
+		got.Antenna != want.Antenna || got.RSSI != want.RSSI {
+		t.Errorf("标签 = %+v, 期望 %+v", got, want)
+	}
+
+	if _, err := EncodeTagUpload(TagReport{EPC: "XYZ"}); err == nil {
+		t.Error("无效的 EPC 应返回错误")
+	}
+}
+
+func TestParseTagReportErrors(t *testing.T) {
+	for _, payload := range []string{
+		"00",                       // 过短
+		"0004E200",                 // EPC 不完整
+		"0002E2003000010300",       // TID 长度字段不完整
+		"0002E200300001030004E280", // TID 数据不完整
+	} {
+		if _, err := ParseTagReport(testutil.MustHex(t, payload)); err == nil {
+			t.Errorf("ParseTagReport(%s) 应返回错误", payload)
+		}
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	// 无效字节 + 损坏的帧 + 两个粘连的帧，逐字节到达
+	stream := testutil.MustHex(t, "0011"+"5A000102FF0000604C"+"5A000102FF0000604D"+"5A000112010001002BDA")
+	d := NewDecoder(iotest.OneByteReader(bytes.NewReader(stream)))
+
+	var crcErr *CRCError
+	if _, err := d.ReadMessage(); !errors.As(err, &crcErr) {
+		t.Fatalf("错误 = %v, 期望 *CRCError", err)
+	}
+	for _, want := range []string{"5A000102FF0000604D", "5A000112010001002BDA"} {
+		msg, err := d.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage: %v", err)
+		}
+		if got := toHex(msg.Raw); got != want {
+			t.Errorf("帧 = %s, 期望 %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package screen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CmdInstruction 指令命令 ID，数据部分为文本指令 (如 t0.txt="")
+const CmdInstruction = "00"
+
+// SendInstruction 发送文本指令
+func (c *Controller) SendInstruction(instruction string) error {
+	return c.SendCommand(CmdInstruction, instruction)
+}
+
+// quoteText 将文本转换为指令中的字符串常量
+//
+// 屏幕命令文档不在仓库中，字符串常量中是否有转义规则没有依据，所以不做转义:
+// 含有双引号的文本会提前结束字符串，返回错误；其他字符 (包括反斜杠) 原样发送。
+func quoteText(text string) (string, error) {
+	if strings.Contains(text, `"`) {
+		return "", fmt.Errorf("文本不能包含双引号: %q", text)
+	}
+	return `"` + text + `"`, nil
+}
+
+// validControl 检查控件名，避免把指令分隔符拼进命令
+func validControl(name string) error {
+	if name == "" {
+		return fmt.Errorf("控件名不能为空")
+	}
+	if strings.ContainsAny(name, " \t\r\n=,\"") {
+		return fmt.Errorf("无效的控件名: %q", name)
+	}
+	return nil
+}
+
+// textInstruction 生成设置文本属性的指令
+func textInstruction(control, text string) (string, error) {
+	quoted, err := quoteText(text)
+	if err != nil {
+		return "", err
+	}
+	return control + ".txt=" + quoted, nil
+}
+
+// valueInstruction 生成设置数值属性的指令
+func valueInstruction(control string, value int) string {
+	return fmt.Sprintf("%s.val=%d", control, value)
+}
+
+// SetText 设置控件的文本属性，如 SetText("t0", "温度") 发送 t0.txt="温度"
+func (c *Controller) SetText(control, text string) error {
+	if err := validControl(control); err != nil {
+		return err
+	}
+	instruction, err := textInstruction(control, text)
+	if err != nil {
+		return err
+	}
+	return c.SendInstruction(instruction)
+}
+
+// SetValue 设置控件的数值属性，如 SetValue("n0", 25) 发送 n0.val=25
+func (c *Controller) SetValue(control string, value int) error {
+	if err := validControl(control); err != nil {
+		return err
+	}
+	return c.SendInstruction(valueInstruction(control, value))
+}
+
+// SetPage 切换页面，page 为页面名或页面编号
+func (c *Controller) SetPage(page string) error {
+	if err := validControl(page); err != nil {
+		return err
+	}
+	return c.SendInstruction("page " + page)
+}
+
+// SetVisible 显示或隐藏控件
+func (c *Controller) SetVisible(control string, visible bool) error {
+	if err := validControl(control); err != nil {
+		return err
+	}
+	v := 0
+	if visible {
+		v = 1
+	}
+	return c.SendInstruction(fmt.Sprintf("vis %s,%d", control, v))
+}
+
+// SetBrightness 设置背光亮度 (0-100)
+func (c *Controller) SetBrightness(percent int) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("背光亮度超出范围 (0-100): %d", percent)
+	}
+	return c.SendInstruction(fmt.Sprintf("dim=%d", percent))
+}
+
+// Beep 蜂鸣器鸣叫指定毫秒数
+func (c *Controller) Beep(ms int) error {
+	if ms <= 0 || ms > 65535 {
+		return fmt.Errorf("蜂鸣时长超出范围 (1-65535 毫秒): %d", ms)
+	}
+	return c.SendInstruction(fmt.Sprintf("beep %d", ms))
+}
@@ -0,0 +1,215 @@
+package screen_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"hardware-test/pkg/internal/testutil"
+	"hardware-test/pkg/screen"
+	"hardware-test/pkg/simulator"
+)
+
+func TestControllerVerifyValue(t *testing.T) {
+	s := simulator.NewScreen()
+	c := testutil.Connect(t, screen.NewControllerWithDialer(testutil.Dial(t, s)))
+
+	if err := c.VerifyValue("sys0", 4321, time.Second); err != nil {
+		t.Fatalf("VerifyValue: %v", err)
+	}
+	if v, _ := s.Value("sys0"); v != 4321 {
+		t.Errorf("模拟屏幕 sys0 = %d, 期望 4321", v)
+	}
+	// 错误中带有收到的原始应答，便于对照真机
+	if err := c.VerifyValue("nope", 1, time.Second); err == nil || !strings.Contains(err.Error(), "EE 02 EE 1A FF FC") {
+		t.Errorf("错误 = %v, 期望带有原始应答", err)
+	}
+}
+
+func TestControllerTestConnection(t *testing.T) {
+	// 当前页面上没有 t0 等控件，连接测试只读写 sys0，不应被其他控件的错误应答影响。
+	// 应答延迟使错误应答在查询发出之后才到达，不会被查询前的 Flush 丢弃。
+	s := simulator.NewScreen()
+	s.SetControls()
+	s.SetFaults(simulator.Faults{Delay: 50 * time.Millisecond})
+	c := screen.NewControllerWithDialer(testutil.Dial(t, s))
+	if ok, err := c.TestConnection(); !ok || err != nil {
+		t.Fatalf("TestConnection = %v, %v", ok, err)
+	}
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Disconnect()
+	if err := c.SetText("t0", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetText("t0.txt", time.Second); err == nil {
+		t.Error("不存在的控件应返回错误")
+	}
+}
+
+func TestControllerText(t *testing.T) {
+	s := simulator.NewScreen()
+	c := testutil.Connect(t, screen.NewControllerWithDialer(testutil.Dial(t, s)))
+
+	if err := c.SetText("t0", "你好"); err != nil {
+		t.Fatalf("SetText: %v", err)
+	}
+	text, err := c.GetText("t0.txt", time.Second)
+	if err != nil {
+		t.Fatalf("GetText: %v", err)
+	}
+	if text != "你好" {
+		t.Errorf("文本 = %q, 期望 %q", text, "你好")
+	}
+}
+
+func TestControllerSpecialText(t *testing.T) {
+	s := simulator.NewScreen()
+	c := testutil.Connect(t, screen.NewControllerWithDialer(testutil.Dial(t, s)))
+
+	// 反斜杠原样发送和保存，双引号在发送前被拒绝
+	if err := c.SetText("t0", `"a"`); err == nil {
+		t.Error("含双引号的文本应返回错误")
+	}
+	want := `C:\dir`
+	if err := c.SetText("t0", want); err != nil {
+		t.Fatalf("SetText: %v", err)
+	}
+	got, err := c.GetText("t0.txt", time.Second)
+	if err != nil {
+		t.Fatalf("GetText: %v", err)
+	}
+	if stored, _ := s.Text("t0.txt"); got != want || stored != want {
+		t.Errorf("读回 %q, 模拟屏幕保存 %q, 期望 %q", got, stored, want)
+	}
+}
+
+func TestControllerPage(t *testing.T) {
+	s := simulator.NewScreen()
+	s.SetAckSuccess(true)
+	c := testutil.Connect(t, screen.NewControllerWithDialer(testutil.Dial(t, s)))
+
+	for _, page := range []string{"1", "main"} {
+		if err := c.SetPage(page); err != nil {
+			t.Fatalf("SetPage(%q): %v", page, err)
+		}
+		// 读回一个变量，确认 page 指令已被执行
+		if _, err := c.GetValue("sys0", time.Second); err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Page(); got != page {
+			t.Errorf("页面 = %q, 期望 %q", got, page)
+		}
+	}
+}
+
+func TestControllerProbe(t *testing.T) {
+	c := testutil.Connect(t, screen.NewControllerWithDialer(testutil.Dial(t, simulator.NewScreen())))
+
+	frame, err := c.Probe(time.Second)
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	enc, _ := screen.NewTextEncoder(screen.CharsetGBK, "")
+	if ev, err := screen.ParseEvent(frame, enc); err != nil || ev.Type != screen.EventValue {
+		t.Errorf("Probe 应答 = % X (%v)", frame, err)
+	}
+}
+
+func TestControllerListen(t *testing.T) {
+	s := simulator.NewScreen()
+	c := testutil.Connect(t, screen.NewControllerWithDialer(testutil.Dial(t, s)))
+
+	// 先完成一次查询，确保模拟屏幕已开始服务该连接
+	if _, err := c.GetValue("sys0", time.Second); err != nil {
+		t.Fatalf("GetValue: %v", err)
+	}
+	events, err := c.Listen()
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	if _, err := c.GetValue("sys0", time.Second); err == nil {
+		t.Error("监听期间查询应返回错误")
+	}
+
+	s.Touch(1, 7, true)
+	select {
+	case ev := <-events:
+		if ev.Type != screen.EventButton || ev.Page != 1 || ev.Component != 7 || !ev.Pressed {
+			t.Errorf("事件 = %s", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("未收到按钮事件")
+	}
+
+	c.Disconnect()
+	for range events {
+	}
+	if err := c.ListenErr(); err != nil {
+		t.Errorf("主动断开后 ListenErr = %v", err)
+	}
+}
+
+func TestControllerFaults(t *testing.T) {
+	t.Run("无应答", func(t *testing.T) {
+		s := simulator.NewScreen()
+		s.SetFaults(simulator.Faults{DropRate: 1})
+		c := testutil.Connect(t, screen.NewControllerWithDialer(testutil.Dial(t, s)))
+
+		start := time.Now()
+		if err := c.VerifyValue("sys0", 1, 200*time.Millisecond); !errors.Is(err, screen.ErrNoReply) {
+			t.Errorf("错误 = %v, 期望 ErrNoReply", err)
+		}
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+			t.Errorf("%v 后返回，期望等待到超时", elapsed)
+		}
+	})
+
+	t.Run("数据损坏", func(t *testing.T) {
+		s := simulator.NewScreen()
+		s.SetFaults(simulator.Faults{CorruptRate: 1})
+		c := testutil.Connect(t, screen.NewControllerWithDialer(testutil.Dial(t, s)))
+
+		// 屏幕协议没有校验，损坏的是数据字节: 帧仍能识别，读回的值与写入的不同
+		err := c.VerifyValue("sys0", 1, 200*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "读回值不符") {
+			t.Errorf("错误 = %v, 期望读回值不符", err)
+		}
+	})
+}
+
+// otherProtocol 按另一种协议应答的屏幕: 每收到数据就回复 "OK\r\n"
+type otherProtocol struct{}
+
+func (otherProtocol) Serve(rw io.ReadWriter) error {
+	buf := make([]byte, 256)
+	for {
+		if _, err := rw.Read(buf); err != nil {
+			return err
+		}
+		if _, err := rw.Write([]byte("OK\r\n")); err != nil {
+			return err
+		}
+	}
+}
+
+func TestControllerUnrecognizedReply(t *testing.T) {
+	c := screen.NewControllerWithDialer(testutil.Dial(t, otherProtocol{}))
+
+	// 屏幕有应答只是格式不同，连接测试给出警告但不判为失败
+	if ok, err := c.TestConnection(); !ok || err != nil {
+		t.Fatalf("TestConnection = %v, %v", ok, err)
+	}
+	if got := string(c.LastResponse()); !strings.Contains(got, "OK") {
+		t.Errorf("LastResponse = %q, 期望保留原始应答", got)
+	}
+
+	testutil.Connect(t, c)
+	err := c.VerifyValue("sys0", 1, 200*time.Millisecond)
+	if !errors.Is(err, screen.ErrUnrecognizedReply) || !strings.Contains(err.Error(), "4F 4B 0D 0A") {
+		t.Errorf("错误 = %v, 期望 ErrUnrecognizedReply 并带有原始数据", err)
+	}
+}
@@ -0,0 +1,83 @@
+package screen
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 屏幕文本字符集
+const (
+	CharsetGBK     = "gbk"
+	CharsetGB18030 = "gb18030"
+)
+
+// TextEncoder 屏幕文本编码器
+//
+// 默认使用 GBK。遇到字符集中不存在的字符时，未设置替换字符则返回错误，
+// 否则用替换字符代替。
+type TextEncoder struct {
+	charset    string
+	enc        encoding.Encoding
+	substitute []byte
+}
+
+// NewTextEncoder 创建文本编码器，substitute 为空表示无法编码时返回错误
+func NewTextEncoder(charset, substitute string) (*TextEncoder, error) {
+	e := &TextEncoder{charset: strings.ToLower(charset)}
+	switch e.charset {
+	case CharsetGBK, "":
+		e.charset = CharsetGBK
+		e.enc = simplifiedchinese.GBK
+	case CharsetGB18030:
+		e.enc = simplifiedchinese.GB18030
+	default:
+		return nil, fmt.Errorf("不支持的字符集: %s (应为 gbk 或 gb18030)", charset)
+	}
+
+	if substitute != "" {
+		sub, err := e.enc.NewEncoder().String(substitute)
+		if err != nil {
+			return nil, fmt.Errorf("替换字符 %q 无法使用 %s 编码", substitute, e.charset)
+		}
+		e.substitute = []byte(sub)
+	}
+	return e, nil
+}
+
+// defaultEncoder 默认编码器: GBK，无法编码时返回错误
+var defaultEncoder, _ = NewTextEncoder(CharsetGBK, "")
+
+// Encode 将字符串编码为屏幕使用的字节
+func (e *TextEncoder) Encode(s string) ([]byte, error) {
+	result := make([]byte, 0, len(s)*2)
+	encoder := e.enc.NewEncoder()
+	for i, r := range s {
+		// ASCII 在 GBK/GB18030 中保持单字节
+		if r < 0x80 {
+			result = append(result, byte(r))
+			continue
+		}
+
+		b, err := encoder.Bytes([]byte(string(r)))
+		if err != nil {
+			if e.substitute == nil {
+				return nil, fmt.Errorf("字符 %q (位置 %d) 无法使用 %s 编码", r, i, strings.ToUpper(e.charset))
+			}
+			b = e.substitute
+		}
+		result = append(result, b...)
+	}
+	return result, nil
+}
+
+// Decode 将屏幕上报的字节解码为字符串
+func (e *TextEncoder) Decode(data []byte) (string, error) {
+	s, err := e.enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("%s 解码失败: %w", strings.ToUpper(e.charset), err)
+	}
+	return string(s), nil
+}
@@ -0,0 +1,324 @@
+package screen
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// 帧格式: EE + 长度(1) + EE + 类型(1) + 数据(N) + FF + FC
+// 长度 = 类型(1) + 数据(N) + FF(1)，与 generateCommand 相同。
+const (
+	frameHead    byte = 0xEE
+	frameDataEnd byte = 0xFF
+	frameTail    byte = 0xFC
+
+	// frameOverhead 帧中长度字段未计入的字节: EE + 长度 + EE + FC
+	frameOverhead = 4
+)
+
+// 屏幕上报的事件类型码
+//
+// 应答和事件的类型码以及应答同样使用 EE…FF FC 帧，取自 TJC (淘晶驰) 系列串口屏的约定，
+// 尚未用本项目所用屏幕的命令文档 (/packages/screen/docs/串口屏命令文档.md，不在仓库中)
+// 或真机应答核对。模拟屏幕 (pkg/simulator) 按同样的约定应答，模拟测试不能证明类型码正确；
+// 查询失败时错误中带有收到的原始数据，便于对照真机应答修正。
+const (
+	codeInvalidInstruction byte = 0x00
+	codeSuccess            byte = 0x01
+	codeInvalidComponent   byte = 0x02
+	codeInvalidPage        byte = 0x03
+	codeInvalidVariable    byte = 0x1A
+	codeInvalidOperation   byte = 0x1B
+	codeTouch              byte = 0x65
+	codePage               byte = 0x66
+	codeText               byte = 0x70
+	codeValue              byte = 0x71
+)
+
+// EventType 事件类型
+type EventType int
+
+const (
+	EventUnknown EventType = iota
+	EventButton            // 按钮按下/释放
+	EventPage              // 页面切换
+	EventText              // 文本输入 / 文本属性查询结果
+	EventValue             // 数值输入 / 数值属性查询结果
+	EventAck               // 指令执行结果
+)
+
+// String 返回事件类型名称
+func (t EventType) String() string {
+	switch t {
+	case EventButton:
+		return "按钮"
+	case EventPage:
+		return "页面"
+	case EventText:
+		return "文本"
+	case EventValue:
+		return "数值"
+	case EventAck:
+		return "应答"
+	default:
+		return "未知"
+	}
+}
+
+// Event 屏幕上报的事件
+type Event struct {
+	Type      EventType
+	Code      byte      // 原始类型码
+	Page      int       // 页面编号 (按钮、页面事件)
+	Component int       // 控件编号 (按钮事件)
+	Pressed   bool      // true 按下, false 释放 (按钮事件)
+	Text      string    // 文本 (文本事件)
+	Value     int32     // 数值 (数值事件)
+	OK        bool      // 指令是否执行成功 (应答事件)
+	Raw       []byte    // 原始帧
+	Time      time.Time // 收到时间
+}
+
+// String 返回事件的可读描述
+func (e Event) String() string {
+	switch e.Type {
+	case EventButton:
+		action := "释放"
+		if e.Pressed {
+			action = "按下"
+		}
+		return fmt.Sprintf("按钮 页面=%d 控件=%d %s", e.Page, e.Component, action)
+	case EventPage:
+		return fmt.Sprintf("页面切换 页面=%d", e.Page)
+	case EventText:
+		return fmt.Sprintf("文本 %q", e.Text)
+	case EventValue:
+		return fmt.Sprintf("数值 %d", e.Value)
+	case EventAck:
+		if e.OK {
+			return "指令执行成功"
+		}
+		return fmt.Sprintf("指令执行失败 (%s)", ackReason(e.Code))
+	default:
+		return fmt.Sprintf("未知事件 0x%02X: % X", e.Code, e.Raw)
+	}
+}
+
+// ackReason 返回应答错误码的说明
+func ackReason(code byte) string {
+	switch code {
+	case codeInvalidInstruction:
+		return "无效指令"
+	case codeInvalidComponent:
+		return "无效控件"
+	case codeInvalidPage:
+		return "无效页面"
+	case codeInvalidVariable:
+		return "无效变量"
+	case codeInvalidOperation:
+		return "无效操作"
+	default:
+		return fmt.Sprintf("错误码 0x%02X", code)
+	}
+}
+
+// ParseEvent 解析一个完整的屏幕帧，文本使用 enc 解码
+func ParseEvent(frame []byte, enc *TextEncoder) (Event, error) {
+	if len(frame) < frameOverhead+2 {
+		return Event{}, fmt.Errorf("帧过短: %d 字节", len(frame))
+	}
+	if frame[0] != frameHead || frame[2] != frameHead {
+		return Event{}, fmt.Errorf("帧头错误: % X", frame[:3])
+	}
+	if n := int(frame[1]); len(frame) != n+frameOverhead {
+		return Event{}, fmt.Errorf("帧长度不符: 长度字段 %d, 帧长 %d 字节", n, len(frame))
+	}
+	if frame[len(frame)-2] != frameDataEnd || frame[len(frame)-1] != frameTail {
+		return Event{}, fmt.Errorf("帧尾错误: % X", frame[len(frame)-2:])
+	}
+
+	ev := Event{
+		Code: frame[3],
+		Raw:  append([]byte(nil), frame...),
+		Time: time.Now(),
+	}
+	data := frame[4 : len(frame)-2]
+
+	switch ev.Code {
+	case codeTouch:
+		if len(data) != 3 {
+			return Event{}, fmt.Errorf("按钮事件数据长度错误: %d", len(data))
+		}
+		ev.Type = EventButton
+		ev.Page, ev.Component, ev.Pressed = int(data[0]), int(data[1]), data[2] == 0x01
+	case codePage:
+		if len(data) != 1 {
+			return Event{}, fmt.Errorf("页面事件数据长度错误: %d", len(data))
+		}
+		ev.Type = EventPage
+		ev.Page = int(data[0])
+	case codeText:
+		text, err := enc.Decode(data)
+		if err != nil {
+			return Event{}, err
+		}
+		ev.Type = EventText
+		ev.Text = text
+	case codeValue:
+		if len(data) != 4 {
+			return Event{}, fmt.Errorf("数值事件数据长度错误: %d", len(data))
+		}
+		ev.Type = EventValue
+		ev.Value = int32(binary.LittleEndian.Uint32(data))
+	case codeSuccess, codeInvalidInstruction, codeInvalidComponent, codeInvalidPage,
+		codeInvalidVariable, codeInvalidOperation:
+		ev.Type = EventAck
+		ev.OK = ev.Code == codeSuccess
+	default:
+		ev.Type = EventUnknown
+	}
+	return ev, nil
+}
+
+// Listen 启动后台读取，将屏幕上报的帧解码为事件并通过返回的 channel 发布
+//
+// 断开连接或读取出错时 channel 关闭，读取错误可通过 ListenErr 获取。
+// 无法解析的帧会被跳过。
+func (c *Controller) Listen() (<-chan Event, error) {
+	if !c.isConnected {
+		return nil, fmt.Errorf("未连接")
+	}
+	if c.listening {
+		return nil, fmt.Errorf("已在监听屏幕事件")
+	}
+
+	events := make(chan Event, 16)
+	done := make(chan struct{})
+	frames := NewFrameReader(c.conn)
+	enc := c.encoder
+
+	c.listening = true
+	c.done = done
+	c.setListenErr(nil)
+
+	go func() {
+		defer close(events)
+		for {
+			frame, err := frames.ReadFrame()
+			if err != nil {
+				select {
+				case <-done:
+					// 主动断开连接，不是错误
+				default:
+					c.setListenErr(err)
+				}
+				return
+			}
+
+			ev, err := ParseEvent(frame, enc)
+			if err != nil {
+				continue
+			}
+			select {
+			case events <- ev:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// ListenErr 返回后台读取结束的原因，主动断开连接时为 nil
+func (c *Controller) ListenErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.listenErr
+}
+
+func (c *Controller) setListenErr(err error) {
+	c.mu.Lock()
+	c.listenErr = err
+	c.mu.Unlock()
+}
+
+// FrameReader 从数据流中切分 EE…FC 帧
+//
+// 帧头之前的无效字节和帧尾不正确的数据会被丢弃，粘连的多个帧依次返回。
+type FrameReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+// NewFrameReader 创建帧读取器
+func NewFrameReader(r io.Reader) *FrameReader {
+	return &FrameReader{r: r}
+}
+
+// Reset 丢弃已缓冲的数据
+func (fr *FrameReader) Reset() {
+	fr.buf = fr.buf[:0]
+}
+
+// ReadFrame 读取一个完整的帧，底层读取错误 (包括超时) 原样返回
+func (fr *FrameReader) ReadFrame() ([]byte, error) {
+	for {
+		if frame := fr.next(); frame != nil {
+			return frame, nil
+		}
+
+		chunk := make([]byte, 256)
+		n, err := fr.r.Read(chunk)
+		fr.buf = append(fr.buf, chunk[:n]...)
+		if n > 0 {
+			continue
+		}
+		if err == nil {
+			err = io.ErrNoProgress
+		}
+		return nil, err
+	}
+}
+
+// next 从缓冲区中取出一个帧，数据不足时返回 nil
+func (fr *FrameReader) next() []byte {
+	for {
+		start := 0
+		for start < len(fr.buf) && fr.buf[start] != frameHead {
+			start++
+		}
+		fr.buf = fr.buf[start:]
+
+		if len(fr.buf) < 3 {
+			return nil
+		}
+		frameLen := int(fr.buf[1]) + frameOverhead
+		if fr.buf[2] != frameHead || frameLen < frameOverhead+2 {
+			fr.buf = fr.buf[1:]
+			continue
+		}
+		if len(fr.buf) < frameLen {
+			return nil
+		}
+		if fr.buf[frameLen-2] != frameDataEnd || fr.buf[frameLen-1] != frameTail {
+			fr.buf = fr.buf[1:]
+			continue
+		}
+
+		frame := append([]byte(nil), fr.buf[:frameLen]...)
+		fr.buf = fr.buf[frameLen:]
+		return frame
+	}
+}
+
+// EncodeFrame 生成 EE…FC 帧: EE + 长度 + EE + 类型 + 数据 + FF + FC
+//
+// 发送的指令和模拟屏幕的上报使用同一格式。数据长度超过 253 字节时长度字段溢出，由调用方检查。
+func EncodeFrame(typ byte, data []byte) []byte {
+	frame := make([]byte, 0, len(data)+frameOverhead+2)
+	frame = append(frame, frameHead, byte(len(data)+2), frameHead, typ)
+	frame = append(frame, data...)
+	return append(frame, frameDataEnd, frameTail)
+}
@@ -0,0 +1,98 @@
+package screen
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+// 能编码的指令生成的帧都能被解析，数据部分解码后与原指令一致
+func FuzzGenerateCommand(f *testing.F) {
+	for _, s := range []string{`t0.txt=""`, `t0.txt="你好"`, "n0.val=123", "page 2", "\xff", "😀"} {
+		f.Add("00", s)
+	}
+	f.Add("0", "page 1")
+	f.Add("0G", "page 1")
+	f.Add("0000", "page 1")
+	f.Fuzz(func(t *testing.T, cmdID, command string) {
+		frame, err := generateCommand(defaultEncoder, cmdID, command)
+		if id, hexErr := hex.DecodeString(cmdID); hexErr != nil || len(id) != 1 {
+			if err == nil {
+				t.Fatalf("generateCommand(%q) = %X, 期望返回错误", cmdID, frame)
+			}
+			return
+		}
+		if err != nil {
+			return
+		}
+		if len(frame) > 0xFF+frameOverhead {
+			t.Fatalf("帧长度 %d 超出长度字段范围", len(frame))
+		}
+		if _, err := ParseEvent(frame, defaultEncoder); err != nil && frame[3] != codeTouch && frame[3] != codePage && frame[3] != codeValue {
+			t.Fatalf("ParseEvent(%X): %v", frame, err)
+		}
+		text, err := defaultEncoder.Decode(frame[4 : len(frame)-2])
+		if err != nil || text != command {
+			t.Fatalf("数据解码为 %q (%v), 期望 %q", text, err, command)
+		}
+	})
+}
+
+func FuzzGenerateCommandTooLong(f *testing.F) {
+	f.Add(253)
+	f.Add(254)
+	f.Fuzz(func(t *testing.T, n int) {
+		if n < 0 || n > 4096 {
+			return
+		}
+		frame, err := generateCommand(defaultEncoder, CmdInstruction, string(bytes.Repeat([]byte("a"), n)))
+		if (err == nil) != (n <= maxCommandData) {
+			t.Fatalf("%d 字节的指令: 帧 %d 字节, 错误 %v", n, len(frame), err)
+		}
+	})
+}
+
+// 解析成功的帧重新编码后与原始字节一致
+func FuzzParseEvent(f *testing.F) {
+	for _, s := range []string{"EE05EE65010201FFFC", "EE03EE6603FFFC", "EE06EE70B2E2CAD4FFFC", "EE06EE71FEFFFFFFFFFC", "EE02EE01FFFC", "EE02EE1AFFFC", "EE02EE01FFFD"} {
+		b, _ := hex.DecodeString(s)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, frame []byte) {
+		ev, err := ParseEvent(frame, defaultEncoder)
+		if err != nil {
+			return
+		}
+		if enc := EncodeFrame(ev.Code, frame[4:len(frame)-2]); !bytes.Equal(enc, frame) {
+			t.Fatalf("EncodeFrame = %X, 期望 %X", enc, frame)
+		}
+		_ = ev.String()
+	})
+}
+
+// 任意字节流都能被切分，返回的帧头、长度和帧尾都正确
+func FuzzFrameReader(f *testing.F) {
+	for _, s := range []string{"EE02EE01FFFC", "00FFEE06EE71FFFC0000FFFCEE03EE6603FFFC", "EE09EE6603FFFC", "EEEEEE"} {
+		b, _ := hex.DecodeString(s)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, stream []byte) {
+		fr := NewFrameReader(bytes.NewReader(stream))
+		for i := 0; i <= len(stream); i++ {
+			frame, err := fr.ReadFrame()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadFrame: %v", err)
+			}
+			n := len(frame)
+			if frame[0] != frameHead || frame[2] != frameHead || n != int(frame[1])+frameOverhead ||
+				frame[n-2] != frameDataEnd || frame[n-1] != frameTail {
+				t.Fatalf("帧 = %X", frame)
+			}
+		}
+		t.Fatalf("%d 字节的数据流未在 %d 次读取内结束", len(stream), len(stream)+1)
+	})
+}
@@ -0,0 +1,168 @@
+package screen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"hardware-test/pkg/transport"
+)
+
+// ErrNoReply 查询期间没有收到屏幕的任何数据
+var ErrNoReply = errors.New("屏幕无应答")
+
+// ErrUnrecognizedReply 收到了屏幕的数据，但其中没有可识别的查询结果
+//
+// 应答格式和类型码尚未用真机核对 (见 events.go)，这种情况更可能是协议不一致而不是屏幕故障。
+var ErrUnrecognizedReply = errors.New("屏幕应答无法识别")
+
+// DefaultVerifyVariable 连接测试默认读写的变量，sys0 是屏幕内置的全局数值变量，不依赖页面上的控件
+const DefaultVerifyVariable = "sys0"
+
+// query 发送 get 指令并等待指定类型的应答帧
+//
+// 不能在 Listen 期间使用，因为后台读取会取走应答帧。
+func (c *Controller) query(variable string, want EventType, timeout time.Duration) (Event, error) {
+	if !c.isConnected {
+		return Event{}, fmt.Errorf("未连接")
+	}
+	if c.listening {
+		return Event{}, fmt.Errorf("正在监听屏幕事件，无法查询")
+	}
+	if err := validControl(variable); err != nil {
+		return Event{}, err
+	}
+
+	c.conn.Flush()
+	if err := c.SendInstruction("get " + variable); err != nil {
+		return Event{}, err
+	}
+
+	c.conn.SetReadDeadline(time.Now().Add(timeout))
+	defer c.conn.SetReadDeadline(time.Time{})
+
+	// 记录收到的所有原始数据，查询失败时放入错误中
+	rec := &recorder{r: c.conn}
+	frames := NewFrameReader(rec)
+	for {
+		frame, err := frames.ReadFrame()
+		if err != nil {
+			if transport.IsTimeout(err) {
+				if len(rec.data) == 0 {
+					return Event{}, fmt.Errorf("%w: %v 内未收到 %s 的查询结果", ErrNoReply, timeout, variable)
+				}
+				c.lastResp = rec.data
+				return Event{}, fmt.Errorf("%w: %v 内未收到 %s 的查询结果 (%s)", ErrUnrecognizedReply, timeout, variable, rec)
+			}
+			return Event{}, fmt.Errorf("%w (%s)", err, rec)
+		}
+
+		ev, err := ParseEvent(frame, c.encoder)
+		if err != nil {
+			continue
+		}
+		switch {
+		case ev.Type == want:
+			c.lastResp = ev.Raw
+			return ev, nil
+		case ev.Type == EventAck && !ev.OK:
+			return Event{}, fmt.Errorf("查询 %s 失败: %s (%s)", variable, ackReason(ev.Code), rec)
+		}
+		// 其他事件 (如之前指令的应答、按钮事件) 跳过
+	}
+}
+
+// recorder 记录从 r 读到的所有数据
+type recorder struct {
+	r    io.Reader
+	data []byte
+}
+
+func (rec *recorder) Read(p []byte) (int, error) {
+	n, err := rec.r.Read(p)
+	rec.data = append(rec.data, p[:n]...)
+	return n, err
+}
+
+// String 返回收到的原始数据，用于错误信息
+func (rec *recorder) String() string {
+	if len(rec.data) == 0 {
+		return "未收到任何数据"
+	}
+	return fmt.Sprintf("收到: % X", rec.data)
+}
+
+// GetValue 查询数值变量或控件属性，如 GetValue("n0.val", time.Second)
+func (c *Controller) GetValue(variable string, timeout time.Duration) (int32, error) {
+	ev, err := c.query(variable, EventValue, timeout)
+	if err != nil {
+		return 0, err
+	}
+	return ev.Value, nil
+}
+
+// GetText 查询文本属性，如 GetText("t0.txt", time.Second)
+func (c *Controller) GetText(variable string, timeout time.Duration) (string, error) {
+	ev, err := c.query(variable, EventText, timeout)
+	if err != nil {
+		return "", err
+	}
+	return ev.Text, nil
+}
+
+// VerifyValue 写入数值后读回并比较，用于确认屏幕确实在处理指令
+func (c *Controller) VerifyValue(variable string, value int32, timeout time.Duration) error {
+	if err := validControl(variable); err != nil {
+		return err
+	}
+	if err := c.SendInstruction(fmt.Sprintf("%s=%d", variable, value)); err != nil {
+		return err
+	}
+
+	got, err := c.GetValue(variable, timeout)
+	if err != nil {
+		return err
+	}
+	if got != value {
+		return fmt.Errorf("%s 读回值不符: 写入 %d, 读回 %d (应答: % X)", variable, value, got, c.lastResp)
+	}
+	return nil
+}
+
+// Probe 发送 get 查询，收到任何有效的屏幕帧 (包括指令错误应答) 时返回该帧
+//
+// 不输出日志，用于扫描时判断对端是否为串口屏。与发送的指令相同的帧视为回显，会被跳过。
+func (c *Controller) Probe(timeout time.Duration) ([]byte, error) {
+	if !c.isConnected {
+		return nil, fmt.Errorf("未连接")
+	}
+
+	cmd, err := generateCommand(c.encoder, CmdInstruction, "get "+DefaultVerifyVariable)
+	if err != nil {
+		return nil, err
+	}
+	c.conn.Flush()
+	if _, err := c.Write(cmd); err != nil {
+		return nil, err
+	}
+
+	c.conn.SetReadDeadline(time.Now().Add(timeout))
+	defer c.conn.SetReadDeadline(time.Time{})
+
+	frames := NewFrameReader(c.conn)
+	for {
+		frame, err := frames.ReadFrame()
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(frame, cmd) {
+			continue
+		}
+		if _, err := ParseEvent(frame, c.encoder); err == nil {
+			c.lastResp = frame
+			return frame, nil
+		}
+	}
+}
@@ -1,75 +1,99 @@
 package screen
 
 import (
+	"errors"
 	"fmt"
-	"net"
+	"io"
+	"os"
+	"sync"
 	"time"
 
-	"github.com/tarm/serial"
+	"hardware-test/pkg/codec"
+	"hardware-test/pkg/transport"
 )
 
 // ConnectionType 连接类型
-type ConnectionType string
+type ConnectionType = transport.Type
 
 const (
-	TypeSerial ConnectionType = "serial"
-	TypeSocket ConnectionType = "socket"
+	TypeSerial = transport.TypeSerial
+	TypeSocket = transport.TypeSocket
 )
 
 // Controller 屏幕控制器
 type Controller struct {
 	connType    ConnectionType
-	path        string
-	baudRate    int
-	port        int
-	socketConn  net.Conn
-	serialConn  *serial.Port
+	dial        transport.Dialer
+	conn        transport.Transport
+	encoder     *TextEncoder
+	verifyVar   string
 	isConnected bool
+	lastResp    []byte
+	out         io.Writer
+
+	// 后台事件读取
+	listening bool
+	done      chan struct{}
+	mu        sync.Mutex
+	listenErr error
 }
 
 // NewController 创建屏幕控制器实例
 func NewController(connType ConnectionType, path string, baudRate, port int) *Controller {
+	cfg := transport.Config{
+		Type:     connType,
+		Address:  path,
+		BaudRate: baudRate,
+		Port:     port,
+	}
 	return &Controller{
-		connType: connType,
-		path:     path,
-		baudRate: baudRate,
-		port:     port,
+		connType:  connType,
+		dial:      cfg.Dialer(),
+		encoder:   defaultEncoder,
+		verifyVar: DefaultVerifyVariable,
+		out:       os.Stdout,
 	}
 }
 
-// Connect 连接屏幕
-func (c *Controller) Connect() error {
-	if c.connType == TypeSerial {
-		return c.connectSerial()
-	}
-	return c.connectSocket()
+// NewControllerWithDialer 使用自定义传输创建屏幕控制器实例
+func NewControllerWithDialer(dial transport.Dialer) *Controller {
+	return &Controller{dial: dial, encoder: defaultEncoder, verifyVar: DefaultVerifyVariable, out: os.Stdout}
 }
 
-// connectSerial 串口连接
-func (c *Controller) connectSerial() error {
-	config := &serial.Config{
-		Name:        c.path,
-		Baud:        c.baudRate,
-		ReadTimeout: 5 * time.Second,
-	}
+// SetOutput 设置测试过程信息的输出 (默认标准输出)
+func (c *Controller) SetOutput(w io.Writer) {
+	c.out = w
+}
 
-	conn, err := serial.OpenPort(config)
-	if err != nil {
-		return fmt.Errorf("屏幕串口连接失败: %w", err)
-	}
-	c.serialConn = conn
-	c.isConnected = true
-	return nil
+// SetTextEncoder 设置文本编码器 (默认 GBK，无法编码的字符返回错误)
+func (c *Controller) SetTextEncoder(enc *TextEncoder) {
+	c.encoder = enc
+}
+
+// SetVerifyVariable 设置连接测试时写入并读回的数值变量 (默认 sys0)
+func (c *Controller) SetVerifyVariable(variable string) {
+	c.verifyVar = variable
+}
+
+// LastResponse 返回最近一次查询收到的原始响应帧
+func (c *Controller) LastResponse() []byte {
+	return c.lastResp
 }
 
-// connectSocket Socket 连接
-func (c *Controller) connectSocket() error {
-	addr := fmt.Sprintf("%s:%d", c.path, c.port)
-	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+// Connect 连接屏幕
+func (c *Controller) Connect() error {
+	conn, err := c.dial()
 	if err != nil {
-		return fmt.Errorf("屏幕 Socket 连接失败: %w", err)
+		switch c.connType {
+		case TypeSerial:
+			return fmt.Errorf("屏幕串口连接失败: %w", err)
+		case TypeSocket:
+			return fmt.Errorf("屏幕 Socket 连接失败: %w", err)
+		default:
+			return fmt.Errorf("屏幕连接失败: %w", err)
+		}
 	}
-	c.socketConn = conn
+	c.conn = conn
 	c.isConnected = true
 	return nil
 }
@@ -80,18 +104,12 @@ func (c *Controller) Disconnect() error {
 		return nil
 	}
 
-	var err error
-	if c.serialConn != nil {
-		err = c.serialConn.Close()
-		c.serialConn = nil
-	}
-	if c.socketConn != nil {
-		err2 := c.socketConn.Close()
-		if err == nil {
-			err = err2
-		}
-		c.socketConn = nil
+	if c.listening {
+		close(c.done)
+		c.listening = false
 	}
+	err := c.conn.Close()
+	c.conn = nil
 	c.isConnected = false
 	return err
 }
@@ -101,14 +119,7 @@ func (c *Controller) Write(data []byte) (int, error) {
 	if !c.isConnected {
 		return 0, fmt.Errorf("未连接")
 	}
-
-	if c.serialConn != nil {
-		return c.serialConn.Write(data)
-	}
-	if c.socketConn != nil {
-		return c.socketConn.Write(data)
-	}
-	return 0, fmt.Errorf("未连接")
+	return c.conn.Write(data)
 }
 
 // Read 读取数据
@@ -116,73 +127,32 @@ func (c *Controller) Read(data []byte) (int, error) {
 	if !c.isConnected {
 		return 0, fmt.Errorf("未连接")
 	}
+	return c.conn.Read(data)
+}
+
+// maxCommandData 指令数据的最大长度，长度字段只有 1 字节且包含命令 ID 和 FF
+const maxCommandData = 0xFF - 2
 
-	if c.serialConn != nil {
-		return c.serialConn.Read(data)
+// generateCommand 生成屏幕命令，文本部分使用 enc 编码
+func generateCommand(enc *TextEncoder, cmdID, command string) ([]byte, error) {
+	id, err := codec.DecodeHex(cmdID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的命令 ID %q: %w", cmdID, err)
 	}
-	if c.socketConn != nil {
-		return c.socketConn.Read(data)
+	if len(id) != 1 {
+		return nil, fmt.Errorf("无效的命令 ID %q: 应为 1 字节", cmdID)
 	}
-	return 0, fmt.Errorf("未连接")
-}
 
-// stringToGBKHex 将字符串转换为 GBK 编码的十六进制
-func stringToGBKHex(s string) string {
-	result := ""
-	for _, c := range s {
-		if c < 128 {
-			result += fmt.Sprintf("%02X", c)
-		} else {
-			r := []byte(string(c))
-			for _, b := range r {
-				result += fmt.Sprintf("%02X", b)
-			}
-		}
+	data, err := enc.Encode(command)
+	if err != nil {
+		return nil, err
 	}
-	return result
-}
-
-// generateCommand 生成屏幕命令
-func generateCommand(cmdID, command string) []byte {
-	dataHex := stringToGBKHex(command)
-	frame := "EE" + cmdID + dataHex + "FF"
-
-	length := (len(frame) / 2) - 1
-	lengthHex := fmt.Sprintf("%02X", length)
-
-	fullCmd := "EE" + lengthHex + frame + "FC"
-
-	return hexToBytes(fullCmd)
-}
-
-// hexToBytes 十六进制字符串转字节
-func hexToBytes(hexStr string) []byte {
-	bytes := make([]byte, len(hexStr)/2)
-	for i := 0; i < len(hexStr); i += 2 {
-		var b byte
-		b1 := hexStr[i]
-		b2 := hexStr[i+1]
-
-		switch {
-		case b1 >= '0' && b1 <= '9':
-			b = (b1 - '0') << 4
-		case b1 >= 'A' && b1 <= 'F':
-			b = (b1 - 'A' + 10) << 4
-		case b1 >= 'a' && b1 <= 'f':
-			b = (b1 - 'a' + 10) << 4
-		}
-
-		switch {
-		case b2 >= '0' && b2 <= '9':
-			b |= b2 - '0'
-		case b2 >= 'A' && b2 <= 'F':
-			b |= b2 - 'A' + 10
-		case b2 >= 'a' && b2 <= 'f':
-			b |= b2 - 'a' + 10
-		}
-		bytes[i/2] = b
+	if len(data) > maxCommandData {
+		return nil, fmt.Errorf("指令过长: %d 字节 (最多 %d 字节)", len(data), maxCommandData)
 	}
-	return bytes
+
+	// EE + 长度 + EE + 命令 ID + 数据 + FF + FC
+	return EncodeFrame(id[0], data), nil
 }
 
 // SendCommand 发送命令
@@ -190,23 +160,38 @@ func (c *Controller) SendCommand(cmdID, command string) error {
 	if !c.isConnected {
 		return fmt.Errorf("未连接")
 	}
-	cmd := generateCommand(cmdID, command)
-	_, err := c.Write(cmd)
+	cmd, err := generateCommand(c.encoder, cmdID, command)
+	if err != nil {
+		return fmt.Errorf("生成屏幕命令失败: %w", err)
+	}
+	_, err = c.Write(cmd)
 	return err
 }
 
 // TestConnection 测试连接
+//
+// 写入校验变量后读回比较。不写入其他控件，页面上没有的控件返回的错误应答会被误认为是
+// 校验变量的查询失败。结果分三种:
+//   - 读回的值一致: 通过
+//   - 屏幕有应答但无法识别 (ErrUnrecognizedReply): 应答格式尚未用真机核对，屏幕显然在工作，
+//     输出原始应答作为警告，按写入已送达通过
+//   - 没有任何应答、指令错误应答或读回值不符: 失败
 func (c *Controller) TestConnection() (bool, error) {
 	if err := c.Connect(); err != nil {
 		return false, err
 	}
 	defer c.Disconnect()
 
-	if err := c.SendCommand("00", `t0.txt=""`); err != nil {
-		return false, err
+	// 每次使用不同的值，避免残留的旧应答被误判为通过
+	value := int32(time.Now().UnixNano() % 100000)
+	err := c.VerifyValue(c.verifyVar, value, 3*time.Second)
+	switch {
+	case err == nil:
+		fmt.Fprintf(c.out, "屏幕读回验证通过: %s=%d\n", c.verifyVar, value)
+	case errors.Is(err, ErrUnrecognizedReply):
+		fmt.Fprintf(c.out, "警告: 已写入 %s=%d，屏幕有应答但格式无法识别 (可能是协议不一致)，跳过读回验证: %v\n", c.verifyVar, value, err)
+	default:
+		return false, fmt.Errorf("屏幕读回验证失败: %w", err)
 	}
-
-	time.Sleep(100 * time.Millisecond)
-
 	return true, nil
 }
@@ -0,0 +1,130 @@
+package screen
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"hardware-test/pkg/internal/testutil"
+)
+
+// 指令帧与 testdata/commands.txt 中的黄金样例逐字节比对，样例名称即指令文本，来源见文件头部
+func TestGenerateCommand(t *testing.T) {
+	for _, golden := range testutil.Golden(t, "testdata/commands.txt") {
+		t.Run(golden.Name, func(t *testing.T) {
+			frame, err := generateCommand(defaultEncoder, CmdInstruction, golden.Name)
+			if err != nil {
+				t.Fatalf("generateCommand: %v", err)
+			}
+			if !bytes.Equal(frame, golden.Bytes) {
+				t.Errorf("帧 = % X, 期望 % X (来源: %s)", frame, golden.Bytes, golden.Source)
+			}
+			if enc := EncodeFrame(0x00, frame[4:len(frame)-2]); !bytes.Equal(enc, frame) {
+				t.Errorf("EncodeFrame = % X, 期望 % X", enc, frame)
+			}
+		})
+	}
+}
+
+func TestGenerateCommandUnencodable(t *testing.T) {
+	if _, err := generateCommand(defaultEncoder, CmdInstruction, `t0.txt="😀"`); err == nil {
+		t.Error("GBK 无法编码的字符应返回错误")
+	}
+
+	enc, err := NewTextEncoder(CharsetGBK, "?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := generateCommand(enc, CmdInstruction, `t0.txt="😀"`)
+	if err != nil {
+		t.Fatalf("generateCommand: %v", err)
+	}
+	if !bytes.Contains(frame, []byte(`"?"`)) {
+		t.Errorf("帧 = % X, 期望替换为 ?", frame)
+	}
+}
+
+func TestInstructions(t *testing.T) {
+	text, err := textInstruction("t0", `a\c`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{text, `t0.txt="a\c"`},
+		{valueInstruction("n0", -5), "n0.val=-5"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("指令 = %s, 期望 %s", tt.got, tt.want)
+		}
+	}
+	// 没有转义规则的依据，含双引号的文本不能发送
+	if s, err := textInstruction("t0", `a"b`); err == nil {
+		t.Errorf("textInstruction = %s, 期望返回错误", s)
+	}
+
+	for _, name := range []string{"", "t0 t1", "a=b", `t"0`} {
+		if validControl(name) == nil {
+			t.Errorf("validControl(%q) 应返回错误", name)
+		}
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame string
+		check func(Event) bool
+	}{
+		{"按钮按下", "EE05EE65010201FFFC", func(e Event) bool {
+			return e.Type == EventButton && e.Page == 1 && e.Component == 2 && e.Pressed
+		}},
+		{"页面", "EE03EE6603FFFC", func(e Event) bool { return e.Type == EventPage && e.Page == 3 }},
+		{"文本", "EE06EE70B2E2CAD4FFFC", func(e Event) bool { return e.Type == EventText && e.Text == "测试" }},
+		{"负数", "EE06EE71FEFFFFFFFFFC", func(e Event) bool { return e.Type == EventValue && e.Value == -2 }},
+		{"成功", "EE02EE01FFFC", func(e Event) bool { return e.Type == EventAck && e.OK }},
+		{"变量无效", "EE02EE1AFFFC", func(e Event) bool { return e.Type == EventAck && !e.OK }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := ParseEvent(testutil.MustHex(t, tt.frame), defaultEncoder)
+			if err != nil {
+				t.Fatalf("ParseEvent: %v", err)
+			}
+			if !tt.check(ev) {
+				t.Errorf("事件 = %+v", ev)
+			}
+		})
+	}
+
+	for _, frame := range []string{"EE02EE01FF", "EE03EE01FFFC", "EE02EE01FFFD", "EE04EE650102FFFC"} {
+		if _, err := ParseEvent(testutil.MustHex(t, frame), defaultEncoder); err == nil {
+			t.Errorf("ParseEvent(%s) 应返回错误", frame)
+		}
+	}
+}
+
+func TestFrameReader(t *testing.T) {
+	// 无效字节 + 粘连的两个帧 (数值中含 FF FC) + 长度错误的帧，逐字节到达
+	stream := testutil.MustHex(t, "00FF"+"EE06EE71FFFC0000FFFC"+"EE03EE6603FFFC"+"EE09EE6603FFFC")
+	fr := NewFrameReader(iotest.OneByteReader(bytes.NewReader(stream)))
+
+	for _, want := range []string{"EE06EE71FFFC0000FFFC", "EE03EE6603FFFC"} {
+		frame, err := fr.ReadFrame()
+		if err != nil {
+			t.Fatalf("ReadFrame: %v", err)
+		}
+		if got := strings.ToUpper(hex.EncodeToString(frame)); got != want {
+			t.Errorf("帧 = %s, 期望 %s", got, want)
+		}
+	}
+	if _, err := fr.ReadFrame(); err != io.EOF {
+		t.Errorf("错误 = %v, 期望 io.EOF", err)
+	}
+}
@@ -0,0 +1,179 @@
+package simulator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"hardware-test/pkg/codec"
+	"hardware-test/pkg/lock"
+)
+
+// 锁控板命令 (与 lock 包生成的命令一致，所有字节异或校验):
+//
+//	查询:     80 01 00 33 + 校验       (1 号板状态)
+//	查询板:   80 + 板地址 + 01 + 校验
+//	开锁:     8A + 板地址 + 锁地址 + 11 + 校验
+const (
+	lockCmdStatus byte = 0x80
+	lockCmdOpen   byte = 0x8A
+)
+
+// DefaultLocksPerBoard 每块模拟锁控板的锁数量
+const DefaultLocksPerBoard = 16
+
+// LockBoards 模拟一组锁控板
+type LockBoards struct {
+	faultState
+
+	mu        sync.Mutex
+	boards    map[int][]bool // 板地址 -> 各锁是否打开
+	autoClose time.Duration
+}
+
+// NewLockBoards 创建模拟锁控板，每块板 locks 把锁，初始全部关闭
+func NewLockBoards(boardAddrs []int, locks int) *LockBoards {
+	if locks <= 0 {
+		locks = DefaultLocksPerBoard
+	}
+	lb := &LockBoards{boards: make(map[int][]bool)}
+	for _, addr := range boardAddrs {
+		lb.boards[addr] = make([]bool, locks)
+	}
+	return lb
+}
+
+// SetAutoClose 设置开锁后自动关闭的时间，0 表示保持打开
+func (lb *LockBoards) SetAutoClose(d time.Duration) {
+	lb.mu.Lock()
+	lb.autoClose = d
+	lb.mu.Unlock()
+}
+
+// SetOpen 设置锁的状态
+func (lb *LockBoards) SetOpen(boardAddr, lockAddr int, open bool) error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	locks, ok := lb.boards[boardAddr]
+	if !ok {
+		return fmt.Errorf("板地址 %d 不存在", boardAddr)
+	}
+	if lockAddr < 1 || lockAddr > len(locks) {
+		return fmt.Errorf("锁地址 %d 超出范围 (1-%d)", lockAddr, len(locks))
+	}
+	locks[lockAddr-1] = open
+	return nil
+}
+
+// IsOpen 返回锁是否打开
+func (lb *LockBoards) IsOpen(boardAddr, lockAddr int) bool {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	locks := lb.boards[boardAddr]
+	return lockAddr >= 1 && lockAddr <= len(locks) && locks[lockAddr-1]
+}
+
+// status 返回板的状态帧，板不存在时返回 nil
+func (lb *LockBoards) status(boardAddr int) []byte {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	locks, ok := lb.boards[boardAddr]
+	if !ok {
+		return nil
+	}
+	return lock.EncodeStatus(boardAddr, locks)
+}
+
+// open 打开锁并返回开锁响应帧，板不存在时返回 nil
+func (lb *LockBoards) open(boardAddr, lockAddr int) []byte {
+	lb.mu.Lock()
+	locks, ok := lb.boards[boardAddr]
+	opened := ok && lockAddr >= 1 && lockAddr <= len(locks)
+	if opened {
+		locks[lockAddr-1] = true
+	}
+	autoClose := lb.autoClose
+	lb.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	if opened && autoClose > 0 {
+		time.AfterFunc(autoClose, func() { lb.SetOpen(boardAddr, lockAddr, false) })
+	}
+	return lock.EncodeOpen(boardAddr, lockAddr, opened)
+}
+
+// Serve 处理一个连接上的命令
+//
+// 校验错误的命令和不存在的板地址不应答，与真实锁控板一致。
+func (lb *LockBoards) Serve(rw io.ReadWriter) error {
+	r := bufio.NewReader(rw)
+	w := newFrameWriter(rw, &lb.faultState, corruptLast)
+
+	for {
+		cmd, err := readLockCommand(r)
+		if err != nil {
+			return err
+		}
+		if cmd == nil {
+			continue
+		}
+
+		var reply []byte
+		switch {
+		case cmd[0] == lockCmdOpen:
+			reply = lb.open(int(cmd[1]), int(cmd[2]))
+		case len(cmd) == 5:
+			// 查询命令固定查询 1 号板
+			reply = lb.status(1)
+		default:
+			reply = lb.status(int(cmd[1]))
+		}
+		if reply == nil {
+			continue
+		}
+		if err := w.send(reply); err != nil {
+			return err
+		}
+	}
+}
+
+// readLockCommand 读取一条命令，校验错误或未知命令返回 nil
+func readLockCommand(r *bufio.Reader) ([]byte, error) {
+	head, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	var n int
+	switch head {
+	case lockCmdOpen:
+		n = 5
+	case lockCmdStatus:
+		// 第 3 字节区分查询 (00 33) 和查询板 (01)
+		b, err := r.Peek(2)
+		if err != nil {
+			return nil, err
+		}
+		n = 4
+		if b[1] == 0x00 {
+			n = 5
+		}
+	default:
+		return nil, nil
+	}
+
+	cmd := make([]byte, n)
+	cmd[0] = head
+	if _, err := io.ReadFull(r, cmd[1:]); err != nil {
+		return nil, err
+	}
+
+	if codec.XOR(cmd[:n-1]) != cmd[n-1] {
+		return nil, nil
+	}
+	return cmd, nil
+}
@@ -0,0 +1,56 @@
+//go:build !windows
+
+package simulator
+
+import (
+	"os"
+	"sync"
+
+	"github.com/creack/pty"
+	"golang.org/x/term"
+)
+
+// PTY 通过伪终端提供模拟设备，客户端把 Path 当作串口打开
+//
+// 伪终端没有波特率，客户端使用任何波特率都能通信。
+type PTY struct {
+	master *os.File
+	slave  *os.File
+	wg     sync.WaitGroup
+}
+
+// OpenPTY 创建伪终端并在其上启动模拟设备
+func OpenPTY(dev Device) (*PTY, error) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		return nil, err
+	}
+	// 原始模式: 不回显、不转换换行，二进制帧原样传输
+	if _, err := term.MakeRaw(int(slave.Fd())); err != nil {
+		master.Close()
+		slave.Close()
+		return nil, err
+	}
+
+	p := &PTY{master: master, slave: slave}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		// 模拟器一直持有从端，客户端关闭后重新打开不会导致主端读取出错
+		dev.Serve(master)
+	}()
+	return p, nil
+}
+
+// Path 返回客户端使用的串口路径 (如 /dev/pts/3)
+func (p *PTY) Path() string {
+	return p.slave.Name()
+}
+
+// Close 关闭伪终端
+func (p *PTY) Close() error {
+	err := p.master.Close()
+	p.slave.Close()
+	p.wg.Wait()
+	return err
+}
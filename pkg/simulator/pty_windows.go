@@ -0,0 +1,21 @@
+package simulator
+
+import "errors"
+
+// PTY 通过伪终端提供模拟设备 (Windows 不支持)
+type PTY struct{}
+
+// OpenPTY 创建伪终端并在其上启动模拟设备 (Windows 不支持)
+func OpenPTY(dev Device) (*PTY, error) {
+	return nil, errors.New("Windows 不支持伪终端")
+}
+
+// Path 返回客户端使用的串口路径
+func (p *PTY) Path() string {
+	return ""
+}
+
+// Close 关闭伪终端
+func (p *PTY) Close() error {
+	return nil
+}
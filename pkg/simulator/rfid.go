@@ -0,0 +1,219 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"hardware-test/pkg/rfid"
+)
+
+// RFID 命令 (消息类别 + MID，与 rfid 包一致)
+const (
+	rfidMIDQueryInfo  byte = 0x00 // 类别 0x01: 查询读写器信息
+	rfidMIDQueryPower byte = 0x02 // 类别 0x02: 查询功率
+	rfidMIDReadEPC    byte = 0x10 // 类别 0x02: 读 EPC
+	rfidMIDStop       byte = 0xFF // 类别 0x02: 停止
+)
+
+// 读 EPC 命令的读取方式
+const rfidReadContinuous byte = 0x01
+
+// DefaultTagInterval 连续盘点时每轮上传标签的间隔
+const DefaultTagInterval = 100 * time.Millisecond
+
+// Tag 模拟读写器能读到的标签
+type Tag struct {
+	EPC     string // 十六进制
+	TID     string // 十六进制，可为空
+	Antenna int
+	RSSI    int
+}
+
+// RFIDReader 模拟 RFID 读写器
+type RFIDReader struct {
+	faultState
+
+	mu       sync.Mutex
+	serial   string
+	power    map[int]int // 天线号 -> 功率 (dBm)
+	tags     []Tag
+	interval time.Duration
+	started  time.Time
+}
+
+// NewRFIDReader 创建模拟读写器，antennas 个天线的功率默认为 30 dBm
+func NewRFIDReader(antennas int) *RFIDReader {
+	r := &RFIDReader{
+		serial:   "SIM-RFID-0001",
+		power:    make(map[int]int),
+		interval: DefaultTagInterval,
+		started:  time.Now(),
+	}
+	for ant := 1; ant <= antennas; ant++ {
+		r.power[ant] = 30
+	}
+	return r
+}
+
+// SetTags 设置读写器能读到的标签
+func (r *RFIDReader) SetTags(tags []Tag) {
+	r.mu.Lock()
+	r.tags = append([]Tag(nil), tags...)
+	r.mu.Unlock()
+}
+
+// SetPower 设置天线功率
+func (r *RFIDReader) SetPower(antenna, dBm int) {
+	r.mu.Lock()
+	r.power[antenna] = dBm
+	r.mu.Unlock()
+}
+
+// SetTagInterval 设置连续盘点时每轮上传标签的间隔
+func (r *RFIDReader) SetTagInterval(d time.Duration) {
+	r.mu.Lock()
+	r.interval = d
+	r.mu.Unlock()
+}
+
+// Serve 处理一个连接上的命令，读 EPC 后在后台上传标签直到收到停止命令
+func (r *RFIDReader) Serve(rw io.ReadWriter) error {
+	dec := rfid.NewDecoder(rw)
+	w := newFrameWriter(rw, &r.faultState, corruptLast)
+
+	var stopInventory func()
+	defer func() {
+		if stopInventory != nil {
+			stopInventory()
+		}
+	}()
+
+	for {
+		msg, err := dec.ReadMessage()
+		if err != nil {
+			var crcErr *rfid.CRCError
+			var lengthErr *rfid.LengthError
+			if errors.As(err, &crcErr) || errors.As(err, &lengthErr) {
+				// 校验错误的命令不应答
+				continue
+			}
+			return err
+		}
+
+		var reply []byte
+		switch {
+		case msg.Category == rfid.CategoryConfig && msg.MID == rfidMIDQueryInfo:
+			reply = rfid.EncodeFrame(msg.Category, msg.MID, false, r.info())
+		case msg.Category == rfid.CategoryRFID && msg.MID == rfidMIDQueryPower:
+			reply = rfid.EncodeFrame(msg.Category, msg.MID, false, r.powerPayload())
+		case msg.Category == rfid.CategoryRFID && msg.MID == rfidMIDReadEPC:
+			if stopInventory != nil {
+				stopInventory()
+			}
+			reply = rfid.EncodeFrame(msg.Category, msg.MID, false, []byte{0x00})
+			if err := w.send(reply); err != nil {
+				return err
+			}
+			stopInventory = r.inventory(w, msg.Payload)
+			continue
+		case msg.Category == rfid.CategoryRFID && msg.MID == rfidMIDStop:
+			if stopInventory != nil {
+				stopInventory()
+				stopInventory = nil
+			}
+			reply = rfid.EncodeFrame(msg.Category, msg.MID, false, []byte{0x00})
+		default:
+			continue
+		}
+		if err := w.send(reply); err != nil {
+			return err
+		}
+	}
+}
+
+// info 返回读写器信息: 序列号长度(2) + 序列号 + 上电时间(4, 秒)
+func (r *RFIDReader) info() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	payload := binary.BigEndian.AppendUint16(nil, uint16(len(r.serial)))
+	payload = append(payload, r.serial...)
+	return binary.BigEndian.AppendUint32(payload, uint32(time.Since(r.started).Seconds()))
+}
+
+// powerPayload 返回各天线功率: (天线号(1) + 功率(1))...，与 rfid.ParsePower 的格式相同 (尚未用真机核对)
+func (r *RFIDReader) powerPayload() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var payload []byte
+	for ant := 1; ant <= 32; ant++ {
+		if p, ok := r.power[ant]; ok {
+			payload = append(payload, byte(ant), byte(p))
+		}
+	}
+	return payload
+}
+
+// inventory 按读 EPC 命令的天线掩码上传标签，返回停止函数
+//
+// 单次读取上传一轮后发送读卡结束；连续读取每隔 interval 上传一轮，停止时发送读卡结束。
+func (r *RFIDReader) inventory(w *frameWriter, params []byte) (stop func()) {
+	var mask uint32
+	continuous := false
+	if len(params) >= 4 {
+		mask = binary.BigEndian.Uint32(params)
+	}
+	if len(params) >= 5 {
+		continuous = params[4] == rfidReadContinuous
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer w.send(rfid.EncodeReadEnd())
+
+		for {
+			r.mu.Lock()
+			tags := append([]Tag(nil), r.tags...)
+			interval := r.interval
+			r.mu.Unlock()
+
+			for _, tag := range tags {
+				if tag.Antenna < 1 || tag.Antenna > 32 || mask&(1<<(tag.Antenna-1)) == 0 {
+					continue
+				}
+				frame, err := rfid.EncodeTagUpload(rfid.TagReport{
+					EPC:     tag.EPC,
+					PC:      uint16(len(tag.EPC)/4) << 11,
+					TID:     tag.TID,
+					Antenna: tag.Antenna,
+					RSSI:    tag.RSSI,
+				})
+				if err != nil {
+					continue
+				}
+				if w.send(frame) != nil {
+					return
+				}
+			}
+
+			if !continuous {
+				return
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-finished
+	}
+}
@@ -0,0 +1,249 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"hardware-test/pkg/screen"
+)
+
+// 屏幕帧类型 (与 screen 包一致)
+const (
+	screenInstruction        byte = 0x00
+	screenInvalidInstruction byte = 0x00
+	screenSuccess            byte = 0x01
+	screenInvalidVariable    byte = 0x1A
+	screenTouch              byte = 0x65
+	screenText               byte = 0x70
+	screenValue              byte = 0x71
+)
+
+// Screen 模拟串口屏，保存变量和控件属性
+//
+// 支持 get、赋值 (x=1, t0.txt="…")、page (页面编号或页面名)、vis、beep 指令；
+// 控件属性 (name.val / name.txt) 和 sys0-sys2 可直接使用，其他变量返回变量无效。
+// 用 SetControls 限定页面上的控件后，其他控件的属性也返回变量无效。
+type Screen struct {
+	faultState
+
+	mu       sync.Mutex
+	enc      *screen.TextEncoder
+	values   map[string]int32
+	texts    map[string]string
+	page     string
+	ack      bool
+	controls map[string]bool
+	writers  map[*frameWriter]struct{}
+}
+
+// NewScreen 创建模拟屏幕，文本使用 GBK 编码
+func NewScreen() *Screen {
+	enc, _ := screen.NewTextEncoder(screen.CharsetGBK, "")
+	return &Screen{
+		enc:     enc,
+		values:  map[string]int32{"sys0": 0, "sys1": 0, "sys2": 0, "dim": 100},
+		texts:   make(map[string]string),
+		page:    "0",
+		writers: make(map[*frameWriter]struct{}),
+	}
+}
+
+// SetAckSuccess 设置指令执行成功时是否应答 0x01 (默认只应答错误)
+func (s *Screen) SetAckSuccess(ack bool) {
+	s.mu.Lock()
+	s.ack = ack
+	s.mu.Unlock()
+}
+
+// SetControls 设置页面上存在的控件，不调用时任何控件名都有效
+func (s *Screen) SetControls(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.controls = make(map[string]bool, len(names))
+	for _, name := range names {
+		s.controls[name] = true
+	}
+}
+
+// hasControl 判断控件属性 (如 t0.txt) 所属的控件是否存在
+func (s *Screen) hasControl(property string) bool {
+	if s.controls == nil {
+		return true
+	}
+	name, _, _ := strings.Cut(property, ".")
+	return s.controls[name]
+}
+
+// Value 返回数值变量
+func (s *Screen) Value(name string) (int32, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[name]
+	return v, ok
+}
+
+// Text 返回文本属性
+func (s *Screen) Text(name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.texts[name]
+	return t, ok
+}
+
+// Page 返回当前页面 (page 指令的参数，页面名或页面编号)
+func (s *Screen) Page() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.page
+}
+
+// Touch 向所有连接上报按钮事件
+func (s *Screen) Touch(page, component int, pressed bool) {
+	state := byte(0)
+	if pressed {
+		state = 1
+	}
+	s.broadcast(screen.EncodeFrame(screenTouch, []byte{byte(page), byte(component), state}))
+}
+
+func (s *Screen) broadcast(frame []byte) {
+	s.mu.Lock()
+	writers := make([]*frameWriter, 0, len(s.writers))
+	for w := range s.writers {
+		writers = append(writers, w)
+	}
+	s.mu.Unlock()
+	for _, w := range writers {
+		w.send(frame)
+	}
+}
+
+// Serve 处理一个连接上的指令
+func (s *Screen) Serve(rw io.ReadWriter) error {
+	frames := screen.NewFrameReader(rw)
+	w := newFrameWriter(rw, &s.faultState, corruptScreenData)
+
+	s.mu.Lock()
+	s.writers[w] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.writers, w)
+		s.mu.Unlock()
+	}()
+
+	for {
+		frame, err := frames.ReadFrame()
+		if err != nil {
+			return err
+		}
+		if len(frame) < 6 || frame[3] != screenInstruction {
+			continue
+		}
+		text, err := s.enc.Decode(frame[4 : len(frame)-2])
+		if err != nil {
+			continue
+		}
+
+		reply := s.execute(text)
+		if reply == nil {
+			continue
+		}
+		if err := w.send(reply); err != nil {
+			return err
+		}
+	}
+}
+
+// corruptScreenData 翻转最后一个数据字节，没有数据时翻转帧类型
+//
+// 帧尾 FF FC 保持不变，损坏的帧仍能被识别为一帧，但数值、文本或应答类型与实际不符。
+func corruptScreenData(frame []byte) int {
+	return len(frame) - 3
+}
+
+// execute 执行一条指令，返回应答帧 (无需应答时为 nil)
+func (s *Screen) execute(instruction string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ok := func() []byte {
+		if s.ack {
+			return screen.EncodeFrame(screenSuccess, nil)
+		}
+		return nil
+	}
+	fail := func(code byte) []byte {
+		return screen.EncodeFrame(code, nil)
+	}
+
+	if name, found := strings.CutPrefix(instruction, "get "); found {
+		name = strings.TrimSpace(name)
+		if strings.HasSuffix(name, ".txt") {
+			if !s.hasControl(name) {
+				return fail(screenInvalidVariable)
+			}
+			data, err := s.enc.Encode(s.texts[name])
+			if err != nil {
+				return fail(screenInvalidVariable)
+			}
+			return screen.EncodeFrame(screenText, data)
+		}
+		v, known := s.values[name]
+		if !known && !(strings.HasSuffix(name, ".val") && s.hasControl(name)) {
+			return fail(screenInvalidVariable)
+		}
+		return screen.EncodeFrame(screenValue, binary.LittleEndian.AppendUint32(nil, uint32(v)))
+	}
+
+	if name, value, found := strings.Cut(instruction, "="); found {
+		if strings.HasPrefix(value, `"`) {
+			text, valid := unquoteText(value)
+			if !valid {
+				return fail(screenInvalidInstruction)
+			}
+			if !s.hasControl(name) {
+				return fail(screenInvalidVariable)
+			}
+			s.texts[name] = text
+			return ok()
+		}
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return fail(screenInvalidInstruction)
+		}
+		if _, known := s.values[name]; !known && !(strings.HasSuffix(name, ".val") && s.hasControl(name)) {
+			return fail(screenInvalidVariable)
+		}
+		s.values[name] = int32(n)
+		return ok()
+	}
+
+	cmd, arg, _ := strings.Cut(instruction, " ")
+	switch cmd {
+	case "page":
+		// 页面编号或页面名
+		if arg == "" || strings.ContainsAny(arg, " \t\"") {
+			return fail(screenInvalidInstruction)
+		}
+		s.page = arg
+		return ok()
+	case "vis", "beep":
+		return ok()
+	}
+	return fail(screenInvalidInstruction)
+}
+
+// unquoteText 解析指令中的字符串常量
+//
+// 与 screen 包一致，不处理转义: 引号之间的内容原样保存，其中不能再有双引号。
+func unquoteText(value string) (string, bool) {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return "", false
+	}
+	text := value[1 : len(value)-1]
+	return text, !strings.Contains(text, `"`)
+}
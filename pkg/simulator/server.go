@@ -0,0 +1,73 @@
+package simulator
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+// Server 在 TCP 端口上提供模拟设备
+type Server struct {
+	ln  net.Listener
+	dev Device
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// ListenTCP 在指定地址 (如 "127.0.0.1:9101"，端口为 0 时自动分配) 上启动模拟设备
+func ListenTCP(addr string, dev Device) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{ln: ln, dev: dev, conns: make(map[net.Conn]struct{})}
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// Addr 返回监听地址
+func (s *Server) Addr() *net.TCPAddr {
+	return s.ln.Addr().(*net.TCPAddr)
+}
+
+// Close 停止监听并关闭所有连接
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.dev.Serve(conn)
+			conn.Close()
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
@@ -0,0 +1,87 @@
+// Package simulator 提供锁控板、RFID 读写器和串口屏的模拟设备，
+// 通过本地 TCP 或伪终端 (pty) 提供与真实设备相同的协议，用于离线开发和自动化测试。
+package simulator
+
+import (
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Device 模拟设备，Serve 处理一个连接直到读取出错
+//
+// 同一个设备可以同时服务多个连接，设备状态在连接之间共享。
+type Device interface {
+	Serve(rw io.ReadWriter) error
+}
+
+// Faults 故障注入配置，作用于设备发出的每一帧
+type Faults struct {
+	Delay       time.Duration // 每帧发送前的延迟
+	DropRate    float64       // 丢弃应答的概率 (0-1)
+	CorruptRate float64       // 破坏应答的概率 (0-1)，翻转的字节由设备决定，见 corruptFunc
+}
+
+// faultState 设备共用的故障注入状态
+type faultState struct {
+	mu     sync.Mutex
+	faults Faults
+}
+
+// SetFaults 设置故障注入，可在运行中修改
+func (f *faultState) SetFaults(faults Faults) {
+	f.mu.Lock()
+	f.faults = faults
+	f.mu.Unlock()
+}
+
+// Faults 返回当前的故障注入配置
+func (f *faultState) Faults() Faults {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.faults
+}
+
+// corruptFunc 返回破坏应答时要翻转的字节位置
+//
+// 锁控板和 RFID 帧以校验结尾，翻转最后一个字节 (corruptLast)；串口屏协议没有校验，
+// 翻转帧尾会让帧无法识别，所以改为翻转数据字节 (见 corruptScreenData)。
+type corruptFunc func(frame []byte) int
+
+// corruptLast 翻转帧的最后一个字节，即锁控板和 RFID 帧的校验
+func corruptLast(frame []byte) int {
+	return len(frame) - 1
+}
+
+// frameWriter 按故障注入配置发送帧，多个协程共用同一连接时保证帧不交错
+type frameWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	faults  *faultState
+	corrupt corruptFunc
+}
+
+func newFrameWriter(w io.Writer, faults *faultState, corrupt corruptFunc) *frameWriter {
+	return &frameWriter{w: w, faults: faults, corrupt: corrupt}
+}
+
+// send 发送一帧，被丢弃时返回 nil
+func (fw *frameWriter) send(frame []byte) error {
+	faults := fw.faults.Faults()
+	if faults.Delay > 0 {
+		time.Sleep(faults.Delay)
+	}
+	if faults.DropRate > 0 && rand.Float64() < faults.DropRate {
+		return nil
+	}
+	if faults.CorruptRate > 0 && rand.Float64() < faults.CorruptRate && len(frame) > 0 {
+		frame = append([]byte(nil), frame...)
+		frame[fw.corrupt(frame)] ^= 0xFF
+	}
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	_, err := fw.w.Write(frame)
+	return err
+}
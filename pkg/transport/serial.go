@@ -0,0 +1,88 @@
+package transport
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tarm/serial"
+)
+
+// serialPollInterval 串口底层读取超时，Read 以此间隔检查截止时间
+const serialPollInterval = 100 * time.Millisecond
+
+// serialTransport 串口传输
+//
+// tarm/serial 只能在打开时设置固定的读取超时，这里以较短的超时轮询，
+// 在其上实现与 net.Conn 一致的读取截止时间。
+type serialTransport struct {
+	port     *serial.Port
+	path     string
+	baudRate int
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+// OpenSerial 打开串口
+func OpenSerial(path string, baudRate int) (Transport, error) {
+	config := &serial.Config{
+		Name:        path,
+		Baud:        baudRate,
+		ReadTimeout: serialPollInterval,
+	}
+
+	port, err := serial.OpenPort(config)
+	if err != nil {
+		return nil, err
+	}
+	return &serialTransport{port: port, path: path, baudRate: baudRate}, nil
+}
+
+func (t *serialTransport) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for {
+		n, err := t.port.Read(p)
+		if n > 0 {
+			return n, nil
+		}
+		// 底层读取超时: Linux 返回 io.EOF，其他平台返回 0 字节
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		t.mu.Lock()
+		deadline := t.deadline
+		t.mu.Unlock()
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+}
+
+func (t *serialTransport) Write(p []byte) (int, error) {
+	return t.port.Write(p)
+}
+
+func (t *serialTransport) Close() error {
+	return t.port.Close()
+}
+
+func (t *serialTransport) SetReadDeadline(deadline time.Time) error {
+	t.mu.Lock()
+	t.deadline = deadline
+	t.mu.Unlock()
+	return nil
+}
+
+func (t *serialTransport) Flush() error {
+	return t.port.Flush()
+}
+
+func (t *serialTransport) String() string {
+	return fmt.Sprintf("serial://%s@%d", t.path, t.baudRate)
+}
@@ -0,0 +1,68 @@
+package transport
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// tcpTransport TCP Socket 传输
+type tcpTransport struct {
+	conn net.Conn
+}
+
+// DialTCP 建立 TCP 连接
+func DialTCP(host string, port int, timeout time.Duration) (Transport, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &tcpTransport{conn: conn}, nil
+}
+
+// NewConn 使用已建立的 net.Conn 创建传输 (用于测试和模拟器)
+func NewConn(conn net.Conn) Transport {
+	return &tcpTransport{conn: conn}
+}
+
+func (t *tcpTransport) Read(p []byte) (int, error) {
+	return t.conn.Read(p)
+}
+
+func (t *tcpTransport) Write(p []byte) (int, error) {
+	return t.conn.Write(p)
+}
+
+func (t *tcpTransport) Close() error {
+	return t.conn.Close()
+}
+
+func (t *tcpTransport) SetReadDeadline(deadline time.Time) error {
+	return t.conn.SetReadDeadline(deadline)
+}
+
+// Flush 读取并丢弃接收缓冲区中已到达的数据，完成后清除读取截止时间
+func (t *tcpTransport) Flush() error {
+	buf := make([]byte, 256)
+	for {
+		if err := t.conn.SetReadDeadline(time.Now().Add(time.Millisecond)); err != nil {
+			return err
+		}
+		n, err := t.conn.Read(buf)
+		if err != nil {
+			if IsTimeout(err) {
+				return t.conn.SetReadDeadline(time.Time{})
+			}
+			return err
+		}
+		if n == 0 {
+			return t.conn.SetReadDeadline(time.Time{})
+		}
+	}
+}
+
+func (t *tcpTransport) String() string {
+	return fmt.Sprintf("socket://%s", t.conn.RemoteAddr())
+}
@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// Type 连接类型
+type Type string
+
+const (
+	TypeSerial Type = "serial"
+	TypeSocket Type = "socket"
+)
+
+// DefaultDialTimeout Socket 连接默认超时
+const DefaultDialTimeout = 5 * time.Second
+
+// Transport 设备传输层，串口和 TCP 使用同一接口
+//
+// 读取截止时间在两种传输上行为一致：截止时间为零值时 Read 一直阻塞到有数据，
+// 超过截止时间返回满足 IsTimeout 的错误。
+type Transport interface {
+	io.ReadWriteCloser
+	// SetReadDeadline 设置读取截止时间，零值表示不超时
+	SetReadDeadline(t time.Time) error
+	// Flush 丢弃尚未读取的数据
+	Flush() error
+	// String 返回连接的可读描述
+	String() string
+}
+
+// Config 传输层配置
+type Config struct {
+	Type     Type
+	Address  string // 串口路径或主机地址
+	Port     int    // Socket 端口
+	BaudRate int    // 串口波特率
+}
+
+// Dialer 建立传输连接的函数
+type Dialer func() (Transport, error)
+
+// Open 根据配置建立连接
+func Open(cfg Config) (Transport, error) {
+	switch cfg.Type {
+	case TypeSerial:
+		return OpenSerial(cfg.Address, cfg.BaudRate)
+	case TypeSocket:
+		return DialTCP(cfg.Address, cfg.Port, DefaultDialTimeout)
+	default:
+		return nil, fmt.Errorf("未知连接类型: %q", cfg.Type)
+	}
+}
+
+// Dialer 返回按此配置建立连接的 Dialer
+func (c Config) Dialer() Dialer {
+	return func() (Transport, error) {
+		return Open(c)
+	}
+}
+
+// String 返回配置的可读描述
+func (c Config) String() string {
+	if c.Type == TypeSocket {
+		return fmt.Sprintf("socket://%s", net.JoinHostPort(c.Address, fmt.Sprint(c.Port)))
+	}
+	return fmt.Sprintf("serial://%s@%d", c.Address, c.BaudRate)
+}
+
+// IsTimeout 判断错误是否为读取超时
+func IsTimeout(err error) bool {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
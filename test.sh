@@ -13,7 +13,7 @@ echo ""
 # 检查是否已编译
 if [ ! -f "./hardware-test" ]; then
     echo "未找到可执行文件，正在编译..."
-    go build -o hardware-test cmd/main.go
+    go build -o hardware-test ./cmd
     if [ $? -ne 0 ]; then
         echo "编译失败！"
         exit 1
@@ -42,7 +42,7 @@ case $MODULE in
         ;;
     all)
         echo "测试所有模块..."
-        ./hardware-test -module all -host 192.168.1.100 -port 8086 -vid 0x1234 -pid 0x5678
+        ./hardware-test -module all -config config.toml
         ;;
     *)
         echo "用法: $0 [rfid|lock|screen|cardreader|all]"